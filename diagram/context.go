@@ -27,6 +27,8 @@ func NewContext(gtx layout.Context, th *material.Theme, zoom *Zoom, diagram *Dia
 type Transform struct {
 	Dp        int
 	PxPerUnit int
+	// Origin is the diagram position at the top-left of the view.
+	Origin Vector
 }
 
 func NewTransform(gtx layout.Context, zoom *Zoom) Transform {
@@ -36,6 +38,7 @@ func NewTransform(gtx layout.Context, zoom *Zoom) Transform {
 	return Transform{
 		Dp:        gtx.Dp(1),
 		PxPerUnit: px,
+		Origin:    zoom.Offset,
 	}
 }
 
@@ -45,41 +48,29 @@ func (tr *Transform) Px(v Unit) int {
 
 func (tr *Transform) Pt(v Vector) image.Point {
 	return image.Point{
-		X: int(v.X) * tr.PxPerUnit,
-		Y: int(v.Y) * tr.PxPerUnit,
+		X: int(v.X-tr.Origin.X) * tr.PxPerUnit,
+		Y: int(v.Y-tr.Origin.Y) * tr.PxPerUnit,
 	}
 }
 
 func (tr *Transform) FPt(v Vector) f32.Point {
-	return f32.Point{
-		X: float32(int(v.X) * tr.PxPerUnit),
-		Y: float32(int(v.Y) * tr.PxPerUnit),
-	}
+	return layout.FPt(tr.Pt(v))
 }
 
 func (tr *Transform) Inv(p image.Point) Vector {
 	return Vector{
-		X: Unit(p.X / tr.PxPerUnit),
-		Y: Unit(p.Y / tr.PxPerUnit),
+		X: Unit(p.X/tr.PxPerUnit) + tr.Origin.X,
+		Y: Unit(p.Y/tr.PxPerUnit) + tr.Origin.Y,
 	}
 }
 
 func (tr *Transform) FInv(p f32.Point) Vector {
-	return Vector{
-		X: Unit(int(p.X) / tr.PxPerUnit),
-		Y: Unit(int(p.Y) / tr.PxPerUnit),
-	}
+	return tr.Inv(image.Point{X: int(p.X), Y: int(p.Y)})
 }
 
 func (tr *Transform) Bounds(box Box) image.Rectangle {
 	return image.Rectangle{
-		Min: image.Point{
-			X: int(box.Pos.X) * tr.PxPerUnit,
-			Y: int(box.Pos.Y) * tr.PxPerUnit,
-		},
-		Max: image.Point{
-			X: int(box.Pos.X+box.Size.X) * tr.PxPerUnit,
-			Y: int(box.Pos.Y+box.Size.Y) * tr.PxPerUnit,
-		},
+		Min: tr.Pt(box.Pos),
+		Max: tr.Pt(box.Pos.Add(box.Size)),
 	}
 }
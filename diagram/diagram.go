@@ -29,6 +29,19 @@ func NewDemoDiagram() *Diagram {
 	return diagram
 }
 
+// Bounds returns the area covered by the nodes.
+func (diagram *Diagram) Bounds() Box {
+	var bounds Box
+	for i, node := range diagram.Nodes {
+		if i == 0 {
+			bounds = node.Box
+		} else {
+			bounds = bounds.Union(node.Box)
+		}
+	}
+	return bounds
+}
+
 type Node struct {
 	Box
 	Style *Style
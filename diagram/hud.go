@@ -57,6 +57,7 @@ func NewHudManager(theme *material.Theme) *HudManager {
 	m.Add(&ManipulationHud{})
 	m.Add(connectionCreation)
 	m.Add(&ZoomHud{Zoom: &m.Zoom})
+	m.Add(&MinimapHud{Zoom: &m.Zoom})
 
 	return m
 }
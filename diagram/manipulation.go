@@ -105,7 +105,7 @@ func (hud *ManipulationHud) HandleNode(gtx *Context, node *Node) {
 
 func (hud *ManipulationHud) updateDelta(gtx *Context) {
 	lastDelta := hud.appliedDelta
-	newDelta := gtx.Inv(hud.current.Sub(hud.start))
+	newDelta := gtx.Inv(hud.current).Sub(gtx.Inv(hud.start))
 	if lastDelta == newDelta {
 		return
 	}
@@ -7,6 +7,13 @@ type Box struct {
 	Size Vector
 }
 
+// Union returns the smallest box that contains both boxes.
+func (box Box) Union(other Box) Box {
+	min := box.Pos.Min(other.Pos)
+	max := box.Pos.Add(box.Size).Max(other.Pos.Add(other.Size))
+	return Box{Pos: min, Size: max.Sub(min)}
+}
+
 type Vector struct{ X, Y Unit }
 
 func V(x, y Unit) Vector { return Vector{X: x, Y: y} }
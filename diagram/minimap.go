@@ -0,0 +1,189 @@
+package main
+
+import (
+	"image"
+
+	"gioui.org/f32"
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+)
+
+// MinimapHud shows an overview of the diagram in the corner of the view.
+// Clicking or dragging on the minimap moves the view.
+type MinimapHud struct {
+	Zoom *Zoom
+
+	// world is the diagram area shown while dragging, keeping it
+	// fixed avoids the minimap rescaling under the pointer.
+	world Box
+
+	pointer  pointer.ID
+	dragging bool
+}
+
+var (
+	minimapWidth  = unit.Dp(200)
+	minimapHeight = unit.Dp(140)
+	minimapMargin = unit.Dp(8)
+)
+
+// minimap maps between diagram units and minimap pixels.
+type minimap struct {
+	world  Box
+	scale  float32
+	offset f32.Point
+}
+
+func newMinimap(area image.Rectangle, world Box) minimap {
+	scale := min(
+		float32(area.Dx())/float32(world.Size.X),
+		float32(area.Dy())/float32(world.Size.Y),
+	)
+	size := f32.Pt(float32(world.Size.X)*scale, float32(world.Size.Y)*scale)
+	return minimap{
+		world: world,
+		scale: scale,
+		offset: f32.Pt(
+			float32(area.Min.X)+(float32(area.Dx())-size.X)/2,
+			float32(area.Min.Y)+(float32(area.Dy())-size.Y)/2,
+		),
+	}
+}
+
+func (m minimap) Pt(v Vector) f32.Point {
+	return f32.Point{
+		X: float32(v.X-m.world.Pos.X)*m.scale + m.offset.X,
+		Y: float32(v.Y-m.world.Pos.Y)*m.scale + m.offset.Y,
+	}
+}
+
+// Inv returns the diagram position at p, unlike Transform.Inv
+// the result is not snapped to the grid.
+func (m minimap) Inv(p f32.Point) (x, y float32) {
+	return (p.X-m.offset.X)/m.scale + float32(m.world.Pos.X),
+		(p.Y-m.offset.Y)/m.scale + float32(m.world.Pos.Y)
+}
+
+func (m minimap) Bounds(box Box) image.Rectangle {
+	return image.Rectangle{
+		Min: m.Pt(box.Pos).Round(),
+		Max: m.Pt(box.Pos.Add(box.Size)).Round(),
+	}
+}
+
+func (hud *MinimapHud) Layout(gtx *Context) {
+	size := image.Point{
+		X: min(gtx.Context.Dp(minimapWidth), gtx.Constraints.Max.X/3),
+		Y: min(gtx.Context.Dp(minimapHeight), gtx.Constraints.Max.Y/3),
+	}
+	if size.X <= 0 || size.Y <= 0 {
+		return
+	}
+	margin := gtx.Context.Dp(minimapMargin)
+	area := image.Rectangle{
+		Min: gtx.Constraints.Max.Sub(size).Sub(image.Pt(margin, margin)),
+		Max: gtx.Constraints.Max.Sub(image.Pt(margin, margin)),
+	}
+
+	viewport := Box{
+		Pos:  gtx.Origin,
+		Size: gtx.Inv(gtx.Constraints.Max).Sub(gtx.Origin).Add(V(1, 1)),
+	}
+
+	if !hud.dragging {
+		world := viewport
+		if len(gtx.Diagram.Nodes) > 0 {
+			world = world.Union(gtx.Diagram.Bounds())
+		}
+		hud.world = Box{
+			Pos:  world.Pos.Sub(V(1, 1)),
+			Size: world.Size.Add(V(2, 2)),
+		}
+	}
+	m := newMinimap(area, hud.world)
+
+	hud.update(gtx, m, viewport)
+
+	defer clip.Rect(area).Push(gtx.Ops).Pop()
+	paint.FillShape(gtx.Ops, WithAlpha(PanelBackground, 0xE0), clip.Rect(area).Op())
+
+	var conns clip.Path
+	conns.Begin(gtx.Ops)
+	for _, conn := range gtx.Diagram.Connections {
+		conns.MoveTo(m.Pt(conn.From.Position()))
+		conns.LineTo(m.Pt(conn.To.Position()))
+	}
+	paint.FillShape(gtx.Ops, DefaultConnection.Border, clip.Stroke{
+		Path:  conns.End(),
+		Width: float32(gtx.Transform.Dp),
+	}.Op())
+
+	for _, node := range gtx.Diagram.Nodes {
+		fill := node.Style.Fill
+		if gtx.Diagram.Selection.Contains(node) {
+			fill = FocusColor.Fill
+		}
+		FillRect(gtx, m.Bounds(node.Box), fill)
+	}
+
+	FillRectBorder(gtx, m.Bounds(viewport), float32(gtx.Transform.Dp*2), ActiveColor.Border)
+	FillRectBorder(gtx, area, float32(gtx.Transform.Dp), Tango[8].Fill)
+
+	event.Op(gtx.Ops, hud)
+	pointer.CursorPointer.Add(gtx.Ops)
+}
+
+func (hud *MinimapHud) update(gtx *Context, m minimap, viewport Box) {
+	center := func(p f32.Point) {
+		x, y := m.Inv(p)
+		hud.Zoom.Offset = Vector{
+			X: Unit(x - float32(viewport.Size.X)/2),
+			Y: Unit(y - float32(viewport.Size.Y)/2),
+		}
+		gtx.Execute(op.InvalidateCmd{})
+	}
+
+	for {
+		e, ok := gtx.Event(pointer.Filter{
+			Target: hud,
+			Kinds:  pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel,
+		})
+		if !ok {
+			break
+		}
+
+		ev, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+
+		switch ev.Kind {
+		case pointer.Press:
+			if !hud.dragging {
+				hud.pointer = ev.PointerID
+				hud.dragging = true
+				center(ev.Position)
+			}
+		case pointer.Drag:
+			if hud.dragging && ev.PointerID == hud.pointer {
+				center(ev.Position)
+
+				if ev.Priority < pointer.Grabbed {
+					gtx.Execute(pointer.GrabCmd{
+						Tag: hud,
+						ID:  hud.pointer,
+					})
+				}
+			}
+		case pointer.Release, pointer.Cancel:
+			if ev.PointerID == hud.pointer {
+				hud.dragging = false
+				hud.pointer = 0
+			}
+		}
+	}
+}
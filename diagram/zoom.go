@@ -1,7 +1,6 @@
 package main
 
 import (
-	"image"
 	"math"
 
 	"gioui.org/layout"
@@ -10,8 +9,9 @@ import (
 )
 
 type Zoom struct {
-	Level  int
-	Offset image.Point
+	Level int
+	// Offset is the diagram position shown at the top-left of the view.
+	Offset Vector
 }
 
 func (zoom *Zoom) Multiplier() float32 {
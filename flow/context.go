@@ -12,6 +12,9 @@ type Context struct {
 	layout.Context
 	Theme   *Theme
 	Diagram *Diagram
+
+	// Node is the node whose Display is being laid out.
+	Node *Node
 }
 
 func NewContext(gtx layout.Context, th *Theme, zoom *Zoom, diagram *Diagram) *Context {
@@ -26,6 +29,8 @@ func NewContext(gtx layout.Context, th *Theme, zoom *Zoom, diagram *Diagram) *Co
 type Transform struct {
 	Dp        int
 	PxPerUnit int
+	// Offset is the pixel position of the diagram origin.
+	Offset image.Point
 }
 
 func NewTransform(gtx layout.Context, zoom *Zoom) Transform {
@@ -35,6 +40,10 @@ func NewTransform(gtx layout.Context, zoom *Zoom) Transform {
 	return Transform{
 		Dp:        gtx.Dp(1),
 		PxPerUnit: px,
+		Offset: gtx.Constraints.Max.Div(2).Sub(image.Point{
+			X: int(zoom.Center.X * Unit(px)),
+			Y: int(zoom.Center.Y * Unit(px)),
+		}),
 	}
 }
 
@@ -44,41 +53,35 @@ func (tr *Transform) Px(v Unit) float32 {
 
 func (tr *Transform) Pt(v Vector) image.Point {
 	return image.Point{
-		X: int(v.X * Unit(tr.PxPerUnit)),
-		Y: int(v.Y * Unit(tr.PxPerUnit)),
+		X: int(v.X*Unit(tr.PxPerUnit)) + tr.Offset.X,
+		Y: int(v.Y*Unit(tr.PxPerUnit)) + tr.Offset.Y,
 	}
 }
 
 func (tr *Transform) FPt(v Vector) f32.Point {
 	return f32.Point{
-		X: float32(v.X * Unit(tr.PxPerUnit)),
-		Y: float32(v.Y * Unit(tr.PxPerUnit)),
+		X: float32(v.X*Unit(tr.PxPerUnit)) + float32(tr.Offset.X),
+		Y: float32(v.Y*Unit(tr.PxPerUnit)) + float32(tr.Offset.Y),
 	}
 }
 
 func (tr *Transform) Inv(p image.Point) Vector {
 	return Vector{
-		X: Unit(p.X) / Unit(tr.PxPerUnit),
-		Y: Unit(p.Y) / Unit(tr.PxPerUnit),
+		X: Unit(p.X-tr.Offset.X) / Unit(tr.PxPerUnit),
+		Y: Unit(p.Y-tr.Offset.Y) / Unit(tr.PxPerUnit),
 	}
 }
 
 func (tr *Transform) FInv(p f32.Point) Vector {
 	return Vector{
-		X: Unit(p.X) / Unit(tr.PxPerUnit),
-		Y: Unit(p.Y) / Unit(tr.PxPerUnit),
+		X: (Unit(p.X) - Unit(tr.Offset.X)) / Unit(tr.PxPerUnit),
+		Y: (Unit(p.Y) - Unit(tr.Offset.Y)) / Unit(tr.PxPerUnit),
 	}
 }
 
 func (tr *Transform) Bounds(box Box) image.Rectangle {
 	return image.Rectangle{
-		Min: image.Point{
-			X: int(box.Pos.X * Unit(tr.PxPerUnit)),
-			Y: int(box.Pos.Y * Unit(tr.PxPerUnit)),
-		},
-		Max: image.Point{
-			X: int((box.Pos.X + box.Size.X) * Unit(tr.PxPerUnit)),
-			Y: int((box.Pos.Y + box.Size.Y) * Unit(tr.PxPerUnit)),
-		},
+		Min: tr.Pt(box.Pos),
+		Max: tr.Pt(box.Pos.Add(box.Size)),
 	}
 }
@@ -1,5 +1,10 @@
 package main
 
+import (
+	"image"
+	"image/color"
+)
+
 func NewDemoDiagram() *Diagram {
 	diagram := NewDiagram()
 
@@ -50,12 +55,31 @@ func NewDemoDiagram() *Diagram {
 		[]*Port{{Name: "done"}, {Name: "fail"}},
 	)
 
+	notify := diagram.NewNode(
+		Rows{
+			Label("notify"),
+			&TextField{Param: "message", Hint: "message"},
+			&Slider{Param: "days", Min: 1, Max: 60},
+			&CheckBox{Param: "close", Label: "close issue"},
+		},
+		V(22, 10), V(8, 4),
+		[]*Port{{Name: "start"}},
+		[]*Port{{Name: "done"}},
+	)
+	notify.Params["message"] = "This issue is stale."
+	notify.Params["days"] = float32(30)
+
+	_ = diagram.NewNode(&ImagePreview{Image: demoImage()}, V(1, 9), V(8, 4),
+		nil,
+		[]*Port{{Name: "image"}},
+	)
+
 	diagram.Conns = []*Conn{
 		{From: issue.Out[0], To: manageLabels.In[0]},
 		{From: issue.Out[0], To: composeComment.In[0]},
 		{From: issue.Out[0], To: issueFlow.In[0]},
 
-		{From: schedule.Out[0], To: daily.In[0]},
+		{From: schedule.Out[0], To: daily.In[0], Label: "cron"},
 		{From: issueComment.Out[0], To: stale.In[0]},
 
 		{From: manageLabels.Out[0], To: updateLabels.In[0]},
@@ -63,8 +87,19 @@ func NewDemoDiagram() *Diagram {
 
 		{From: composeComment.Out[0], To: createComment.In[0]},
 
-		{From: daily.Out[0], To: stale.In[0]},
+		{From: daily.Out[0], To: stale.In[0], Label: "daily"},
+		{From: stale.Out[0], To: notify.In[0]},
 	}
 
 	return diagram
 }
+
+func demoImage() image.Image {
+	m := image.NewNRGBA(image.Rect(0, 0, 64, 32))
+	for y := range 32 {
+		for x := range 64 {
+			m.SetNRGBA(x, y, color.NRGBA{R: byte(x * 4), G: byte(y * 8), B: 0x80, A: 0xFF})
+		}
+	}
+	return m
+}
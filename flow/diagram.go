@@ -9,8 +9,9 @@ import (
 )
 
 type Diagram struct {
-	Nodes []*Node
-	Conns []*Conn
+	Nodes  []*Node
+	Conns  []*Conn
+	Groups []*Group
 
 	Selection Set
 	Focus     Set
@@ -26,7 +27,11 @@ func NewDiagram() *Diagram {
 type Node struct {
 	Box
 
+	// Group is the innermost group that contains the node.
+	Group *Group
+
 	Display Display
+	Params  Params
 	In      []*Port
 	Out     []*Port
 	Ports   []*Port
@@ -47,17 +52,25 @@ func (p *Port) Position() Vector {
 }
 
 type Conn struct {
-	From *Port
-	To   *Port
+	From  *Port
+	To    *Port
+	Label string
 }
 
 func (diagram *Diagram) NewNode(display Display, pos, size Vector, in []*Port, out []*Port) *Node {
+	node := newNode(display, pos, size, in, out)
+	diagram.Nodes = append(diagram.Nodes, node)
+	return node
+}
+
+func newNode(display Display, pos, size Vector, in []*Port, out []*Port) *Node {
 	node := &Node{
 		Box: Box{
 			Pos:  pos,
 			Size: size,
 		},
 		Display: display,
+		Params:  Params{},
 	}
 
 	for y, p := range in {
@@ -74,11 +87,76 @@ func (diagram *Diagram) NewNode(display Display, pos, size Vector, in []*Port, o
 		node.Ports = append(node.Ports, p)
 	}
 
-	diagram.Nodes = append(diagram.Nodes, node)
-
 	return node
 }
 
+// VisibleNodes returns nodes that are not hidden inside a collapsed group,
+// including the nodes that represent collapsed groups.
+func (diagram *Diagram) VisibleNodes() []*Node {
+	var nodes []*Node
+	for _, node := range diagram.Nodes {
+		if node.Group.Hidden() {
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	for _, group := range diagram.AllGroups() {
+		if group.Collapsed && !group.Parent.Hidden() {
+			nodes = append(nodes, group.Node)
+		}
+	}
+	return nodes
+}
+
+// Bounds returns the area covered by the visible nodes and group frames.
+func (diagram *Diagram) Bounds() Box {
+	var bounds Box
+	include := func(box Box) {
+		if bounds == (Box{}) {
+			bounds = box
+		} else {
+			bounds = bounds.Union(box)
+		}
+	}
+
+	for _, node := range diagram.VisibleNodes() {
+		include(node.Box)
+	}
+	for _, group := range diagram.AllGroups() {
+		if !group.Hidden() {
+			include(group.Bounds())
+		}
+	}
+	return bounds
+}
+
+// VisiblePort returns the port that p is shown as.
+//
+// Ports inside a collapsed group are shown as the corresponding
+// port of the group node. It returns nil when the port is not
+// exposed by the collapsed group.
+func (diagram *Diagram) VisiblePort(p *Port) *Port {
+	collapsed := p.Owner.Group.Outermost()
+	if collapsed == nil {
+		return p
+	}
+	return collapsed.ports[p]
+}
+
+// VisibleConn returns the ports that conn is shown between.
+// It returns false when conn is hidden inside a collapsed group.
+func (diagram *Diagram) VisibleConn(conn *Conn) (from, to *Port, ok bool) {
+	from = diagram.VisiblePort(conn.From)
+	to = diagram.VisiblePort(conn.To)
+	if from == nil || to == nil {
+		return nil, nil, false
+	}
+	if from != conn.From && from.Owner == to.Owner {
+		return nil, nil, false
+	}
+	return from, to, true
+}
+
 type Label string
 
 func (label Label) Layout(gtx *Context) {
@@ -1,31 +1,67 @@
 package main
 
 import (
+	"image"
+
 	"gioui.org/f32"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
 	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
+	"gioui.org/widget/material"
 )
 
+// tabSize is the diameter of the port tabs relative to the unit size.
+const tabSize = 0.6
+
+// tabDepth is how far the port tabs protrude from the node relative to the unit size.
+const tabDepth = 0.75 * 1.4 * tabSize / 2
+
 type NodeLayer struct{}
 
 func (layer *NodeLayer) Layout(gtx *Context) {
-	for _, node := range gtx.Diagram.Nodes {
+	for _, node := range gtx.Diagram.VisibleNodes() {
 		layer.LayoutNode(gtx, node)
 	}
 }
 
+func (layer *NodeLayer) update(gtx *Context, n *Node) {
+	for {
+		e, ok := gtx.Event(pointer.Filter{
+			Target: n,
+			Kinds:  pointer.Press,
+		})
+		if !ok {
+			break
+		}
+
+		ev, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+
+		if ev.Kind == pointer.Press {
+			if ev.Modifiers.Contain(key.ModCtrl) {
+				gtx.Diagram.Selection.Toggle(n)
+			} else if !gtx.Diagram.Selection.Contains(n) {
+				gtx.Diagram.Selection.Set(n)
+			}
+		}
+	}
+}
+
 func (layer *NodeLayer) LayoutNode(gtx *Context, n *Node) {
+	layer.update(gtx, n)
+
 	b := gtx.Bounds(n.Box)
 
 	pxPerUnit := float32(gtx.PxPerUnit)
 
-	const p = 0.6
-	const c = 0.2
-
-	tabR2 := pxPerUnit * p
-	tabP := pxPerUnit * (1.0 - p) / 2.0
+	tabR2 := pxPerUnit * tabSize
+	tabP := pxPerUnit * (1.0 - tabSize) / 2.0
 	portR := pxPerUnit * 0.15
 
 	path := func(ops *op.Ops) clip.PathSpec {
@@ -78,9 +114,16 @@ func (layer *NodeLayer) LayoutNode(gtx *Context, n *Node) {
 	// border
 	func() {
 		defer op.Affine(pixelAlign).Push(gtx.Ops).Pop()
-		paint.FillShape(gtx.Ops, gtx.Theme.Node.Border, clip.Stroke{
+		border, width := gtx.Theme.Node.Border, gtx.Metric.PxPerDp
+		switch {
+		case gtx.Diagram.Selection.Contains(n):
+			border, width = gtx.Theme.Selected.Border, 3*gtx.Metric.PxPerDp
+		case gtx.Diagram.Focus.Contains(n):
+			border, width = gtx.Theme.Active.Border, 3*gtx.Metric.PxPerDp
+		}
+		paint.FillShape(gtx.Ops, border, clip.Stroke{
 			Path:  path(gtx.Ops),
-			Width: gtx.Metric.PxPerDp,
+			Width: width,
 		}.Op())
 	}()
 
@@ -95,6 +138,7 @@ func (layer *NodeLayer) LayoutNode(gtx *Context, n *Node) {
 	}
 
 	defer clip.Rect(b).Op().Push(gtx.Ops).Pop()
+	event.Op(gtx.Ops, n)
 	defer op.Offset(b.Min).Push(gtx.Ops).Pop()
 
 	before := gtx.Constraints
@@ -103,39 +147,200 @@ func (layer *NodeLayer) LayoutNode(gtx *Context, n *Node) {
 	gtx.Constraints.Min = b.Size()
 	gtx.Constraints.Max = b.Size()
 
+	gtx.Node = n
+	defer func() { gtx.Node = nil }()
+
 	n.Display.Layout(gtx)
 }
 
-type ConnLayer struct{}
+type ConnLayer struct {
+	Hover *Conn
+
+	// labels contains label bounds from the last layout.
+	labels map[*Conn]image.Rectangle
+}
 
 func (layer *ConnLayer) Layout(gtx *Context) {
+	layer.update(gtx)
+
+	if layer.labels == nil {
+		layer.labels = make(map[*Conn]image.Rectangle)
+	}
+	clear(layer.labels)
+
 	for _, conn := range gtx.Diagram.Conns {
-		layer.LayoutConn(gtx, conn)
+		if _, _, ok := gtx.Diagram.VisibleConn(conn); ok {
+			layer.LayoutConn(gtx, conn)
+		}
+	}
+	for _, conn := range gtx.Diagram.Conns {
+		if _, _, ok := gtx.Diagram.VisibleConn(conn); ok && conn.Label != "" {
+			layer.LayoutLabel(gtx, conn)
+		}
+	}
+
+	defer clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops).Pop()
+	defer pointer.PassOp{}.Push(gtx.Ops).Pop()
+	event.Op(gtx.Ops, layer)
+	if layer.Hover != nil {
+		pointer.CursorPointer.Add(gtx.Ops)
 	}
 }
 
-func (layer *ConnLayer) LayoutConn(gtx *Context, conn *Conn) {
-	connectionWidth := gtx.PxPerUnit / 8
+func (layer *ConnLayer) update(gtx *Context) {
+	for {
+		e, ok := gtx.Event(pointer.Filter{
+			Target: layer,
+			Kinds:  pointer.Move | pointer.Press | pointer.Leave,
+		})
+		if !ok {
+			break
+		}
 
-	from := gtx.FPt(conn.From.Position())
-	to := gtx.FPt(conn.To.Position()).Sub(from)
+		ev, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
 
-	curveOffset := f32.Point{X: float32(to.X) / 2}
+		switch ev.Kind {
+		case pointer.Move:
+			layer.Hover = layer.Hit(gtx, ev.Position)
+		case pointer.Leave:
+			layer.Hover = nil
+		case pointer.Press:
+			conn := layer.Hit(gtx, ev.Position)
+			layer.Hover = conn
+			switch {
+			case conn == nil:
+				if !ev.Modifiers.Contain(key.ModCtrl) {
+					gtx.Diagram.Selection.Clear()
+				}
+			case ev.Modifiers.Contain(key.ModCtrl):
+				gtx.Diagram.Selection.Toggle(conn)
+			default:
+				gtx.Diagram.Selection.Set(conn)
+			}
+		}
+	}
+}
 
-	path := func(ops *op.Ops) clip.PathSpec {
-		var p clip.Path
-		p.Begin(ops)
-		p.MoveTo(from)
-		if to.X != 0 {
-			p.Cube(curveOffset, to.Sub(curveOffset), to)
-		} else {
-			p.Line(to)
+// Hit returns the topmost connection under pos.
+func (layer *ConnLayer) Hit(gtx *Context, pos f32.Point) *Conn {
+	tolerance := connWidth(gtx)/2 + float32(gtx.Transform.Dp*4)
+	for i := len(gtx.Diagram.Conns) - 1; i >= 0; i-- {
+		conn := gtx.Diagram.Conns[i]
+		if _, _, ok := gtx.Diagram.VisibleConn(conn); !ok {
+			continue
+		}
+		if pos.Round().In(layer.labels[conn]) {
+			return conn
+		}
+		if ConnCurve(gtx, conn).Distance(pos, 32) <= tolerance {
+			return conn
 		}
-		return p.End()
 	}
+	return nil
+}
 
-	paint.FillShape(gtx.Ops, gtx.Theme.Conn.Border, clip.Stroke{
-		Path:  path(gtx.Ops),
-		Width: float32(connectionWidth),
+func (layer *ConnLayer) LayoutConn(gtx *Context, conn *Conn) {
+	curve := ConnCurve(gtx, conn)
+	width := connWidth(gtx)
+	arrow := arrowSize(gtx)
+
+	style := layer.connStyle(gtx, conn)
+	if style != gtx.Theme.Conn {
+		paint.FillShape(gtx.Ops, style.Fill, clip.Stroke{
+			Path:  curve.Path(gtx.Ops),
+			Width: width * 3,
+		}.Op())
+	}
+
+	paint.FillShape(gtx.Ops, style.Border, clip.Stroke{
+		Path:  curve.Path(gtx.Ops),
+		Width: width,
 	}.Op())
+
+	// arrowhead
+	tip := curve.To.Add(f32.Pt(arrow, 0))
+	var p clip.Path
+	p.Begin(gtx.Ops)
+	p.MoveTo(tip)
+	p.LineTo(curve.To.Add(f32.Pt(0, -arrow*0.6)))
+	p.LineTo(curve.To.Add(f32.Pt(0, arrow*0.6)))
+	p.Close()
+	paint.FillShape(gtx.Ops, style.Border, clip.Outline{Path: p.End()}.Op())
 }
+
+func (layer *ConnLayer) LayoutLabel(gtx *Context, conn *Conn) {
+	style := layer.connStyle(gtx, conn)
+
+	lgtx := gtx.Context
+	lgtx.Constraints = layout.Constraints{Max: gtx.Constraints.Max}
+
+	macro := op.Record(gtx.Ops)
+	w := material.Caption(gtx.Theme.Theme, conn.Label)
+	w.MaxLines = 1
+	dims := w.Layout(lgtx)
+	call := macro.Stop()
+
+	pad := image.Pt(dims.Size.Y/2, gtx.Transform.Dp*2)
+	mid := ConnCurve(gtx, conn).At(0.5)
+	size := dims.Size.Add(pad.Mul(2))
+	min := image.Pt(int(mid.X), int(mid.Y)).Sub(size.Div(2))
+	r := image.Rectangle{Min: min, Max: min.Add(size)}
+	layer.labels[conn] = r
+
+	rr := r.Dy() / 2
+	paint.FillShape(gtx.Ops, gtx.Theme.Background, clip.UniformRRect(r, rr).Op(gtx.Ops))
+	paint.FillShape(gtx.Ops, style.Border, clip.Stroke{
+		Path:  clip.UniformRRect(r, rr).Path(gtx.Ops),
+		Width: float32(gtx.Transform.Dp),
+	}.Op())
+
+	defer op.Offset(r.Min.Add(pad)).Push(gtx.Ops).Pop()
+	call.Add(gtx.Ops)
+}
+
+// connStyle returns the style for drawing conn.
+func (layer *ConnLayer) connStyle(gtx *Context, conn *Conn) Style {
+	switch {
+	case gtx.Diagram.Selection.Contains(conn):
+		return gtx.Theme.Selected
+	case layer.Hover == conn:
+		return gtx.Theme.Active
+	default:
+		return gtx.Theme.Conn
+	}
+}
+
+// ConnCurve returns the curve of conn, excluding the arrowhead.
+//
+// Connections leave Out ports and enter In ports horizontally,
+// connections going backwards loop around the nodes.
+func ConnCurve(gtx *Context, conn *Conn) Cubic {
+	pxPerUnit := float32(gtx.PxPerUnit)
+
+	fromPort, toPort, ok := gtx.Diagram.VisibleConn(conn)
+	if !ok {
+		fromPort, toPort = conn.From, conn.To
+	}
+
+	from := gtx.FPt(fromPort.Position())
+	to := gtx.FPt(toPort.Position())
+	to.X -= tabDepth*pxPerUnit + arrowSize(gtx)
+
+	offset := max((to.X-from.X)/2, pxPerUnit*2)
+	if to.X < from.X {
+		offset = max(offset, (from.X-to.X)/3)
+	}
+
+	return Cubic{
+		From:  from,
+		Ctrl0: from.Add(f32.Pt(offset, 0)),
+		Ctrl1: to.Sub(f32.Pt(offset, 0)),
+		To:    to,
+	}
+}
+
+func connWidth(gtx *Context) float32 { return float32(gtx.PxPerUnit) / 8 }
+func arrowSize(gtx *Context) float32 { return float32(gtx.PxPerUnit) / 3 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"gioui.org/io/key"
 	"gioui.org/layout"
 	"gioui.org/op/clip"
 )
@@ -24,10 +25,16 @@ func NewEditor(diagram *Diagram) *Editor {
 
 	editor.Zoom.Level = defaultZoom
 
+	bounds := diagram.Bounds()
+	editor.Zoom.Center = bounds.Pos.Add(bounds.Size.Scale(0.5))
+
 	editor.AddLayer(&BackgroundLayer{})
 	editor.AddLayer(&GridLayer{})
+	editor.AddLayer(&GroupLayer{})
 	editor.AddLayer(&ConnLayer{})
 	editor.AddLayer(&NodeLayer{})
+	editor.AddLayer(&MinimapLayer{Zoom: &editor.Zoom})
+	editor.AddLayer(&SearchLayer{Zoom: &editor.Zoom})
 
 	return editor
 }
@@ -39,6 +46,16 @@ func (editor *Editor) AddLayer(layer Layer) {
 func (editor *Editor) Layout(th *Theme, gtx layout.Context) layout.Dimensions {
 	defer clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops).Pop()
 
+	for {
+		ev, ok := gtx.Event(key.Filter{Name: "G", Required: key.ModShortcut})
+		if !ok {
+			break
+		}
+		if ev, ok := ev.(key.Event); ok && ev.State == key.Press {
+			editor.Diagram.GroupSelection()
+		}
+	}
+
 	for _, layer := range editor.Layers {
 		var lgtx layout.Context
 		if editor.Exclusive == nil || editor.Exclusive == layer {
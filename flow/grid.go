@@ -22,9 +22,13 @@ func (*GridLayer) Layout(gtx *Context) {
 	max := image.Point{X: min.X + gtx.Transform.Dp, Y: min.Y + gtx.Transform.Dp}
 
 	scalePx := gtx.PxPerUnit
+	start := image.Point{
+		X: (gtx.Offset.X%scalePx + scalePx) % scalePx,
+		Y: (gtx.Offset.Y%scalePx + scalePx) % scalePx,
+	}
 	var p image.Point
-	for p.X = 0; p.X < gtx.Constraints.Max.X; p.X += scalePx {
-		for p.Y = 0; p.Y < gtx.Constraints.Max.Y; p.Y += scalePx {
+	for p.X = start.X; p.X < gtx.Constraints.Max.X; p.X += scalePx {
+		for p.Y = start.Y; p.Y < gtx.Constraints.Max.Y; p.Y += scalePx {
 			stack := clip.Rect{Min: p.Sub(min), Max: p.Add(max)}.Push(gtx.Ops)
 			paint.PaintOp{}.Add(gtx.Ops)
 			stack.Pop()
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"slices"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// groupPadding is the space between the frame and the member nodes.
+const groupPadding = 1
+
+// Group is a named frame around a set of nodes and nested groups.
+//
+// A collapsed group is shown as a single Node, which exposes
+// the ports that have connections crossing the frame.
+type Group struct {
+	Name   string
+	Parent *Group
+
+	Nodes  []*Node
+	Groups []*Group
+
+	Collapsed bool
+	// Node represents the group while it is collapsed.
+	Node *Node
+
+	// ports maps member ports to the ports of Node.
+	ports map[*Port]*Port
+
+	toggle widget.Clickable
+}
+
+// Group creates a new group from nodes.
+//
+// The group is nested inside the innermost group that contains all the
+// nodes. Members of other groups inside it are included together with
+// their whole group.
+func (diagram *Diagram) Group(name string, nodes []*Node) *Group {
+	var parent *Group
+	for i, node := range nodes {
+		if i == 0 {
+			parent = node.Group
+		} else {
+			parent = commonGroup(parent, node.Group)
+		}
+	}
+
+	// find the direct children of parent that contain the nodes
+	var members []*Node
+	var groups []*Group
+	for _, node := range nodes {
+		var child *Group
+		if represents, ok := node.Display.(*Group); ok {
+			// the group may be nested deeper than the direct child
+			child = represents.ancestorIn(parent)
+		} else if node.Group != parent {
+			child = node.Group.ancestorIn(parent)
+		}
+
+		if child == nil {
+			members = append(members, node)
+		} else if !slices.Contains(groups, child) {
+			groups = append(groups, child)
+		}
+	}
+
+	group := &Group{
+		Name:   name,
+		Parent: parent,
+	}
+	for _, node := range members {
+		group.adoptNode(node)
+	}
+	for _, child := range groups {
+		group.adoptGroup(diagram, child)
+	}
+
+	if parent == nil {
+		diagram.Groups = append(diagram.Groups, group)
+	} else {
+		parent.Groups = append(parent.Groups, group)
+	}
+
+	return group
+}
+
+func (group *Group) adoptNode(node *Node) {
+	if node.Group != nil {
+		node.Group.Nodes = slices.DeleteFunc(node.Group.Nodes, func(n *Node) bool { return n == node })
+	}
+	node.Group = group
+	group.Nodes = append(group.Nodes, node)
+}
+
+func (group *Group) adoptGroup(diagram *Diagram, child *Group) {
+	isChild := func(g *Group) bool { return g == child }
+	if child.Parent != nil {
+		child.Parent.Groups = slices.DeleteFunc(child.Parent.Groups, isChild)
+	} else {
+		diagram.Groups = slices.DeleteFunc(diagram.Groups, isChild)
+	}
+	child.Parent = group
+	if child.Node != nil {
+		child.Node.Group = group
+	}
+	group.Groups = append(group.Groups, child)
+}
+
+// ancestorIn returns the ancestor of group (or group itself)
+// that is directly inside parent.
+func (group *Group) ancestorIn(parent *Group) *Group {
+	for group != nil && group.Parent != parent {
+		group = group.Parent
+	}
+	return group
+}
+
+// commonGroup returns the innermost group that contains both a and b.
+func commonGroup(a, b *Group) *Group {
+	for x := a; x != nil; x = x.Parent {
+		for y := b; y != nil; y = y.Parent {
+			if x == y {
+				return x
+			}
+		}
+	}
+	return nil
+}
+
+// Hidden returns whether the group or any of its parents is collapsed.
+func (group *Group) Hidden() bool {
+	return group.Outermost() != nil
+}
+
+// Outermost returns the outermost collapsed group among group and its parents.
+func (group *Group) Outermost() *Group {
+	var collapsed *Group
+	for ; group != nil; group = group.Parent {
+		if group.Collapsed {
+			collapsed = group
+		}
+	}
+	return collapsed
+}
+
+// AllGroups returns all groups in the diagram, parents before children.
+func (diagram *Diagram) AllGroups() []*Group {
+	var all []*Group
+	var walk func(groups []*Group)
+	walk = func(groups []*Group) {
+		for _, group := range groups {
+			all = append(all, group)
+			walk(group.Groups)
+		}
+	}
+	walk(diagram.Groups)
+	return all
+}
+
+// Contains returns whether node is inside the group or any of its nested groups.
+func (group *Group) Contains(node *Node) bool {
+	for g := node.Group; g != nil; g = g.Parent {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// Bounds returns the area covered by the group frame.
+func (group *Group) Bounds() Box {
+	var bounds Box
+	include := func(box Box) {
+		if bounds == (Box{}) {
+			bounds = box
+		} else {
+			bounds = bounds.Union(box)
+		}
+	}
+
+	for _, node := range group.Nodes {
+		include(node.Box)
+	}
+	for _, child := range group.Groups {
+		if child.Collapsed {
+			include(child.Node.Box)
+		} else {
+			include(child.Bounds())
+		}
+	}
+
+	return Box{
+		Pos:  bounds.Pos.Sub(V(groupPadding, groupPadding+1)),
+		Size: bounds.Size.Add(V(2*groupPadding, 2*groupPadding+1)),
+	}
+}
+
+// Collapse replaces the group with a single node that exposes
+// the ports of connections crossing the frame.
+func (group *Group) Collapse(diagram *Diagram) {
+	var in, out []*Port
+	for _, conn := range diagram.Conns {
+		from, to := group.Contains(conn.From.Owner), group.Contains(conn.To.Owner)
+		switch {
+		case from && !to && !slices.Contains(out, conn.From):
+			out = append(out, conn.From)
+		case !from && to && !slices.Contains(in, conn.To):
+			in = append(in, conn.To)
+		}
+	}
+
+	proxy := func(ports []*Port) []*Port {
+		var proxies []*Port
+		for _, p := range ports {
+			proxies = append(proxies, &Port{Name: p.Name})
+		}
+		return proxies
+	}
+
+	bounds := group.Bounds()
+	size := V(8, Unit(max(len(in), len(out), 1)))
+	group.Node = newNode(group, bounds.Pos, size, proxy(in), proxy(out))
+	group.Node.Group = group.Parent
+
+	group.ports = make(map[*Port]*Port)
+	for i, p := range in {
+		group.ports[p] = group.Node.In[i]
+	}
+	for i, p := range out {
+		group.ports[p] = group.Node.Out[i]
+	}
+
+	group.Collapsed = true
+}
+
+// Expand shows the members of the group in place.
+func (group *Group) Expand() {
+	group.Collapsed = false
+	group.Node = nil
+	group.ports = nil
+}
+
+// Layout implements Display for the node of a collapsed group.
+func (group *Group) Layout(gtx *Context) {
+	layout.Flex{Alignment: layout.Middle}.Layout(gtx.Context,
+		layout.Rigid(func(lgtx layout.Context) layout.Dimensions {
+			return group.layoutToggle(gtx, lgtx)
+		}),
+		layout.Flexed(1, func(lgtx layout.Context) layout.Dimensions {
+			lgtx.Constraints.Max.Y = gtx.PxPerUnit
+			w := material.Body1(gtx.Theme.Theme, group.Name)
+			w.Alignment = text.Middle
+			return w.Layout(lgtx)
+		}),
+	)
+}
+
+// layoutToggle lays out the button for collapsing and expanding the group.
+func (group *Group) layoutToggle(gtx *Context, lgtx layout.Context) layout.Dimensions {
+	if group.toggle.Clicked(lgtx) {
+		if group.Collapsed {
+			group.Expand()
+		} else {
+			group.Collapse(gtx.Diagram)
+		}
+	}
+
+	size := image.Pt(gtx.PxPerUnit, gtx.PxPerUnit)
+	lgtx.Constraints = layout.Exact(size)
+	return group.toggle.Layout(lgtx, func(lgtx layout.Context) layout.Dimensions {
+		glyph := "▼"
+		if group.Collapsed {
+			glyph = "►"
+		}
+		w := material.Body2(gtx.Theme.Theme, glyph)
+		w.Alignment = text.Middle
+		return layout.Center.Layout(lgtx, w.Layout)
+	})
+}
+
+// GroupLayer draws the frames of expanded groups.
+type GroupLayer struct{}
+
+func (layer *GroupLayer) Layout(gtx *Context) {
+	for _, group := range gtx.Diagram.AllGroups() {
+		if group.Hidden() {
+			continue
+		}
+		layer.LayoutGroup(gtx, group)
+	}
+}
+
+func (layer *GroupLayer) LayoutGroup(gtx *Context, group *Group) {
+	b := gtx.Bounds(group.Bounds())
+	radius := gtx.PxPerUnit / 4
+	style := gtx.Theme.Group
+
+	paint.FillShape(gtx.Ops, style.Fill, clip.UniformRRect(b, radius).Op(gtx.Ops))
+
+	header := b
+	header.Max.Y = header.Min.Y + gtx.PxPerUnit
+	func() {
+		defer clip.UniformRRect(b, radius).Push(gtx.Ops).Pop()
+		paint.FillShape(gtx.Ops, style.Mid, clip.Rect(header).Op())
+	}()
+
+	paint.FillShape(gtx.Ops, style.Border, clip.Stroke{
+		Path:  clip.UniformRRect(b, radius).Path(gtx.Ops),
+		Width: float32(gtx.Transform.Dp),
+	}.Op())
+
+	defer clip.Rect(header).Push(gtx.Ops).Pop()
+	defer op.Offset(header.Min).Push(gtx.Ops).Pop()
+
+	lgtx := gtx.Context
+	lgtx.Constraints = layout.Exact(header.Size())
+	layout.Flex{Alignment: layout.Middle}.Layout(lgtx,
+		layout.Rigid(func(lgtx layout.Context) layout.Dimensions {
+			return group.layoutToggle(gtx, lgtx)
+		}),
+		layout.Rigid(func(lgtx layout.Context) layout.Dimensions {
+			return material.Body1(gtx.Theme.Theme, group.Name).Layout(lgtx)
+		}),
+	)
+}
+
+// GroupSelection groups the selected nodes into a new frame.
+func (diagram *Diagram) GroupSelection() *Group {
+	var nodes []*Node
+	for _, node := range diagram.VisibleNodes() {
+		if diagram.Selection.Contains(node) {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	group := diagram.Group(fmt.Sprintf("group %d", len(diagram.AllGroups())+1), nodes)
+	diagram.Selection.Clear()
+	return group
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGroupCollapsedNestedWithOutsideNode(t *testing.T) {
+	diagram := NewDiagram()
+	a := diagram.NewNode(Label("a"), V(0, 0), V(4, 2), nil, nil)
+	b := diagram.NewNode(Label("b"), V(0, 4), V(4, 2), nil, nil)
+	outside := diagram.NewNode(Label("outside"), V(10, 0), V(4, 2), nil, nil)
+
+	inner := diagram.Group("inner", []*Node{a})
+	outer := diagram.Group("outer", []*Node{a, b})
+	if inner.Parent != outer {
+		t.Fatalf("inner should be nested in outer")
+	}
+	inner.Collapse(diagram)
+
+	group := diagram.Group("all", []*Node{inner.Node, outside})
+
+	if group.Parent != nil {
+		t.Errorf("expected a top-level group, got parent %q", group.Parent.Name)
+	}
+	if inner.Parent != outer || !slices.Contains(outer.Groups, inner) {
+		t.Errorf("inner was taken out of outer")
+	}
+	if outer.Parent != group || !slices.Contains(group.Groups, outer) {
+		t.Errorf("outer should be moved into the new group as a whole")
+	}
+	if outside.Group != group {
+		t.Errorf("outside node should be in the new group")
+	}
+	if !group.Contains(a) || !group.Contains(b) {
+		t.Errorf("the new group should contain all the members of outer")
+	}
+}
@@ -15,6 +15,13 @@ type Box struct {
 	Size Vector
 }
 
+// Union returns the smallest box that contains both boxes.
+func (box Box) Union(other Box) Box {
+	min := box.Pos.Min(other.Pos)
+	max := box.Pos.Add(box.Size).Max(other.Pos.Add(other.Size))
+	return Box{Pos: min, Size: max.Sub(min)}
+}
+
 type Vector struct{ X, Y Unit }
 
 func V(x, y Unit) Vector { return Vector{X: x, Y: y} }
@@ -33,6 +40,13 @@ func (v Vector) Sub(b Vector) Vector {
 	}
 }
 
+func (v Vector) Scale(s Unit) Vector {
+	return Vector{
+		X: v.X * s,
+		Y: v.Y * s,
+	}
+}
+
 func (v Vector) Min(b Vector) Vector {
 	return Vector{
 		X: minUnit(v.X, b.X),
@@ -114,3 +128,59 @@ func (c Circle) Path(ops *op.Ops) clip.PathSpec {
 	)
 	return p.End()
 }
+
+// Cubic is a cubic bezier curve in pixel coordinates.
+type Cubic struct {
+	From, Ctrl0, Ctrl1, To f32.Point
+}
+
+// At returns the point on the curve at t in [0, 1].
+func (c Cubic) At(t float32) f32.Point {
+	s := 1 - t
+	a := s * s * s
+	b := 3 * s * s * t
+	d := 3 * s * t * t
+	e := t * t * t
+	return f32.Point{
+		X: a*c.From.X + b*c.Ctrl0.X + d*c.Ctrl1.X + e*c.To.X,
+		Y: a*c.From.Y + b*c.Ctrl0.Y + d*c.Ctrl1.Y + e*c.To.Y,
+	}
+}
+
+// Path returns the PathSpec for the curve.
+func (c Cubic) Path(ops *op.Ops) clip.PathSpec {
+	var p clip.Path
+	p.Begin(ops)
+	p.MoveTo(c.From)
+	p.CubeTo(c.Ctrl0, c.Ctrl1, c.To)
+	return p.End()
+}
+
+// Distance returns the approximate distance from pt to the curve
+// by flattening the curve into the specified number of segments.
+func (c Cubic) Distance(pt f32.Point, segments int) float32 {
+	best := float32(math.Inf(1))
+	prev := c.From
+	for i := 1; i <= segments; i++ {
+		next := c.At(float32(i) / float32(segments))
+		best = min(best, segmentDistance(pt, prev, next))
+		prev = next
+	}
+	return best
+}
+
+// segmentDistance returns the distance from pt to the line segment a-b.
+func segmentDistance(pt, a, b f32.Point) float32 {
+	ab := b.Sub(a)
+	ap := pt.Sub(a)
+
+	length2 := ab.X*ab.X + ab.Y*ab.Y
+	t := float32(0)
+	if length2 > 0 {
+		t = (ap.X*ab.X + ap.Y*ab.Y) / length2
+		t = max(0, min(t, 1))
+	}
+
+	d := ap.Sub(ab.Mul(t))
+	return float32(math.Hypot(float64(d.X), float64(d.Y)))
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"image"
+
+	"gioui.org/f32"
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+)
+
+// MinimapLayer shows an overview of the diagram in the corner of the view.
+// Clicking or dragging on the minimap moves the Zoom center.
+type MinimapLayer struct {
+	Zoom *Zoom
+
+	// world is the diagram area shown while dragging, keeping it
+	// fixed avoids the minimap rescaling under the pointer.
+	world Box
+
+	pointer  pointer.ID
+	dragging bool
+}
+
+var (
+	minimapWidth  = unit.Dp(200)
+	minimapHeight = unit.Dp(140)
+	minimapMargin = unit.Dp(8)
+)
+
+// minimap maps between diagram units and minimap pixels.
+type minimap struct {
+	world  Box
+	scale  float32
+	offset f32.Point
+}
+
+func newMinimap(area image.Rectangle, world Box) minimap {
+	scale := min(
+		float32(area.Dx())/float32(world.Size.X),
+		float32(area.Dy())/float32(world.Size.Y),
+	)
+	size := f32.Pt(float32(world.Size.X)*scale, float32(world.Size.Y)*scale)
+	return minimap{
+		world: world,
+		scale: scale,
+		offset: f32.Pt(
+			float32(area.Min.X)+(float32(area.Dx())-size.X)/2,
+			float32(area.Min.Y)+(float32(area.Dy())-size.Y)/2,
+		),
+	}
+}
+
+func (m minimap) Pt(v Vector) f32.Point {
+	return f32.Point{
+		X: float32(v.X-m.world.Pos.X)*m.scale + m.offset.X,
+		Y: float32(v.Y-m.world.Pos.Y)*m.scale + m.offset.Y,
+	}
+}
+
+func (m minimap) Inv(p f32.Point) Vector {
+	return Vector{
+		X: Unit((p.X-m.offset.X)/m.scale) + m.world.Pos.X,
+		Y: Unit((p.Y-m.offset.Y)/m.scale) + m.world.Pos.Y,
+	}
+}
+
+func (m minimap) Bounds(box Box) image.Rectangle {
+	return image.Rectangle{
+		Min: m.Pt(box.Pos).Round(),
+		Max: m.Pt(box.Pos.Add(box.Size)).Round(),
+	}
+}
+
+func (layer *MinimapLayer) Layout(gtx *Context) {
+	size := image.Point{
+		X: min(gtx.Context.Dp(minimapWidth), gtx.Constraints.Max.X/3),
+		Y: min(gtx.Context.Dp(minimapHeight), gtx.Constraints.Max.Y/3),
+	}
+	if size.X <= 0 || size.Y <= 0 {
+		return
+	}
+	margin := gtx.Context.Dp(minimapMargin)
+	area := image.Rectangle{
+		Min: gtx.Constraints.Max.Sub(size).Sub(image.Pt(margin, margin)),
+		Max: gtx.Constraints.Max.Sub(image.Pt(margin, margin)),
+	}
+
+	viewport := Box{
+		Pos: gtx.Inv(image.Point{}),
+	}
+	viewport.Size = gtx.Inv(gtx.Constraints.Max).Sub(viewport.Pos)
+
+	if !layer.dragging {
+		world := viewport
+		if bounds := gtx.Diagram.Bounds(); bounds != (Box{}) {
+			world = world.Union(bounds)
+		}
+		layer.world = Box{
+			Pos:  world.Pos.Sub(V(1, 1)),
+			Size: world.Size.Add(V(2, 2)),
+		}
+	}
+	m := newMinimap(area, layer.world)
+
+	layer.update(gtx, m)
+
+	defer clip.Rect(area).Push(gtx.Ops).Pop()
+	paint.FillShape(gtx.Ops, WithAlpha(gtx.Theme.Background, 0xE0), clip.Rect(area).Op())
+
+	for _, group := range gtx.Diagram.AllGroups() {
+		if group.Hidden() {
+			continue
+		}
+		FillRect(gtx, m.Bounds(group.Bounds()), gtx.Theme.Group.Mid)
+	}
+
+	var conns clip.Path
+	conns.Begin(gtx.Ops)
+	for _, conn := range gtx.Diagram.Conns {
+		from, to, ok := gtx.Diagram.VisibleConn(conn)
+		if !ok {
+			continue
+		}
+		conns.MoveTo(m.Pt(from.Position()))
+		conns.LineTo(m.Pt(to.Position()))
+	}
+	paint.FillShape(gtx.Ops, gtx.Theme.Conn.Border, clip.Stroke{
+		Path:  conns.End(),
+		Width: float32(gtx.Transform.Dp),
+	}.Op())
+
+	for _, node := range gtx.Diagram.VisibleNodes() {
+		style := gtx.Theme.Node
+		if gtx.Diagram.Selection.Contains(node) || gtx.Diagram.Focus.Contains(node) {
+			style = gtx.Theme.Selected
+		}
+		FillRect(gtx, m.Bounds(node.Box), style.Mid)
+	}
+
+	FillRectBorder(gtx, m.Bounds(viewport), float32(gtx.Transform.Dp*2), gtx.Theme.Active.Border)
+	FillRectBorder(gtx, area, float32(gtx.Transform.Dp), gtx.Theme.Grid)
+
+	event.Op(gtx.Ops, layer)
+	pointer.CursorPointer.Add(gtx.Ops)
+}
+
+func (layer *MinimapLayer) update(gtx *Context, m minimap) {
+	for {
+		e, ok := gtx.Event(pointer.Filter{
+			Target: layer,
+			Kinds:  pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel,
+		})
+		if !ok {
+			break
+		}
+
+		ev, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+
+		switch ev.Kind {
+		case pointer.Press:
+			if !layer.dragging {
+				layer.pointer = ev.PointerID
+				layer.dragging = true
+				layer.Zoom.Center = m.Inv(ev.Position)
+				gtx.Execute(op.InvalidateCmd{})
+			}
+		case pointer.Drag:
+			if layer.dragging && ev.PointerID == layer.pointer {
+				layer.Zoom.Center = m.Inv(ev.Position)
+				gtx.Execute(op.InvalidateCmd{})
+
+				if ev.Priority < pointer.Grabbed {
+					gtx.Execute(pointer.GrabCmd{
+						Tag: layer,
+						ID:  layer.pointer,
+					})
+				}
+			}
+		case pointer.Release, pointer.Cancel:
+			if ev.PointerID == layer.pointer {
+				layer.dragging = false
+				layer.pointer = 0
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"image"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Params contains the parameter values of a node.
+type Params map[string]any
+
+// String returns the string parameter name.
+func (params Params) String(name string) string {
+	v, _ := params[name].(string)
+	return v
+}
+
+// Float returns the numeric parameter name.
+func (params Params) Float(name string) float32 {
+	v, _ := params[name].(float32)
+	return v
+}
+
+// Bool returns the boolean parameter name.
+func (params Params) Bool(name string) bool {
+	v, _ := params[name].(bool)
+	return v
+}
+
+// Rows lays out displays below each other, each taking one unit.
+type Rows []Display
+
+func (rows Rows) Layout(gtx *Context) {
+	before := gtx.Constraints
+	defer func() { gtx.Constraints = before }()
+
+	size := image.Pt(before.Max.X, gtx.PxPerUnit)
+	gtx.Constraints = layout.Exact(size)
+	for i, row := range rows {
+		func() {
+			defer op.Offset(image.Pt(0, i*gtx.PxPerUnit)).Push(gtx.Ops).Pop()
+			defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+			row.Layout(gtx)
+		}()
+	}
+}
+
+// TextField is an editable single line text parameter.
+type TextField struct {
+	Param string
+	Hint  string
+
+	editor widget.Editor
+}
+
+func (field *TextField) Layout(gtx *Context) {
+	params := gtx.Node.Params
+	field.editor.SingleLine = true
+
+	for {
+		ev, ok := field.editor.Update(gtx.Context)
+		if !ok {
+			break
+		}
+		if _, ok := ev.(widget.ChangeEvent); ok {
+			params[field.Param] = field.editor.Text()
+		}
+	}
+	if !gtx.Focused(&field.editor) && field.editor.Text() != params.String(field.Param) {
+		field.editor.SetText(params.String(field.Param))
+	}
+
+	layout.Inset{Left: 8, Right: 8}.Layout(gtx.Context, func(lgtx layout.Context) layout.Dimensions {
+		return layout.W.Layout(lgtx, material.Editor(gtx.Theme.Theme, &field.editor, field.Hint).Layout)
+	})
+}
+
+// Slider is a numeric parameter in the range [Min, Max].
+type Slider struct {
+	Param    string
+	Min, Max float32
+
+	float widget.Float
+}
+
+func (slider *Slider) Layout(gtx *Context) {
+	params := gtx.Node.Params
+
+	if slider.float.Update(gtx.Context) {
+		params[slider.Param] = slider.Min + slider.float.Value*(slider.Max-slider.Min)
+	}
+	if !slider.float.Dragging() && slider.Max > slider.Min {
+		slider.float.Value = (params.Float(slider.Param) - slider.Min) / (slider.Max - slider.Min)
+	}
+
+	layout.Flex{Alignment: layout.Middle}.Layout(gtx.Context,
+		layout.Flexed(1, material.Slider(gtx.Theme.Theme, &slider.float).Layout),
+		layout.Rigid(func(lgtx layout.Context) layout.Dimensions {
+			lgtx.Constraints.Min.X = lgtx.Constraints.Max.Y * 2
+			w := material.Body2(gtx.Theme.Theme, fmt.Sprintf("%.3g", params.Float(slider.Param)))
+			w.Alignment = text.Middle
+			return layout.Center.Layout(lgtx, w.Layout)
+		}),
+	)
+}
+
+// CheckBox is a boolean parameter.
+type CheckBox struct {
+	Param string
+	Label string
+
+	bool widget.Bool
+}
+
+func (check *CheckBox) Layout(gtx *Context) {
+	params := gtx.Node.Params
+
+	if check.bool.Update(gtx.Context) {
+		params[check.Param] = check.bool.Value
+	}
+	check.bool.Value = params.Bool(check.Param)
+
+	layout.Inset{Left: 4}.Layout(gtx.Context, func(lgtx layout.Context) layout.Dimensions {
+		return layout.W.Layout(lgtx, material.CheckBox(gtx.Theme.Theme, &check.bool, check.Label).Layout)
+	})
+}
+
+// ImagePreview shows an image scaled to fit the node.
+type ImagePreview struct {
+	Image image.Image
+
+	src   image.Image
+	image paint.ImageOp
+}
+
+func (preview *ImagePreview) Layout(gtx *Context) {
+	if preview.src != preview.Image {
+		preview.src = preview.Image
+		preview.image = paint.NewImageOp(preview.Image)
+	}
+
+	layout.UniformInset(4).Layout(gtx.Context, widget.Image{
+		Src:      preview.image,
+		Fit:      widget.Contain,
+		Position: layout.Center,
+	}.Layout)
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"cmp"
+	"image"
+	"slices"
+	"strings"
+	"unicode"
+
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Searchable is implemented by displays that contain text to search for.
+type Searchable interface {
+	SearchText() []string
+}
+
+func (label Label) SearchText() []string     { return []string{string(label)} }
+func (list List) SearchText() []string       { return list }
+func (group *Group) SearchText() []string    { return []string{group.Name} }
+func (check *CheckBox) SearchText() []string { return []string{check.Label} }
+
+func (rows Rows) SearchText() []string {
+	var texts []string
+	for _, row := range rows {
+		if row, ok := row.(Searchable); ok {
+			texts = append(texts, row.SearchText()...)
+		}
+	}
+	return texts
+}
+
+// SearchResult is a node matching the search query.
+type SearchResult struct {
+	Node *Node
+	// Text is the best matching label or port name.
+	Text string
+	// Port is set when Text is a port name.
+	Port  bool
+	Score int
+}
+
+// Search finds the nodes whose labels or port names match query.
+func (diagram *Diagram) Search(query string) []SearchResult {
+	var results []SearchResult
+	for _, node := range diagram.Nodes {
+		best := SearchResult{Node: node, Score: -1}
+
+		if display, ok := node.Display.(Searchable); ok {
+			for _, text := range display.SearchText() {
+				if score, ok := fuzzyMatch(query, text); ok && score > best.Score {
+					best.Text, best.Port, best.Score = text, false, score
+				}
+			}
+		}
+		for _, port := range node.Ports {
+			if score, ok := fuzzyMatch(query, port.Name); ok && score > best.Score {
+				best.Text, best.Port, best.Score = port.Name, true, score
+			}
+		}
+
+		if best.Score >= 0 {
+			results = append(results, best)
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b SearchResult) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(a.Text, b.Text),
+		)
+	})
+	return results
+}
+
+// fuzzyMatch reports whether the runes of pattern appear in text in order,
+// ignoring case. Consecutive runes and runes at the start of a word score
+// higher, an empty pattern matches everything.
+func fuzzyMatch(pattern, text string) (score int, ok bool) {
+	want := []rune(strings.ToLower(pattern))
+	if len(want) == 0 {
+		return 0, true
+	}
+
+	consecutive := false
+	prev := ' '
+	for _, r := range strings.ToLower(text) {
+		if len(want) > 0 && r == want[0] {
+			score++
+			if consecutive {
+				score += 4
+			}
+			if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+				score += 2
+			}
+			want = want[1:]
+			consecutive = true
+		} else {
+			consecutive = false
+		}
+		prev = r
+	}
+	if len(want) > 0 {
+		return 0, false
+	}
+	return score, true
+}
+
+// SearchLayer is a palette for finding nodes and moving the view to them.
+// It's opened with Ctrl+F.
+//
+// The diagram editor doesn't have one, since its nodes have neither
+// labels nor named ports to search for and there's no Diagram.Focus.
+type SearchLayer struct {
+	Zoom *Zoom
+
+	Open     bool
+	Results  []SearchResult
+	Selected int
+
+	input widget.Editor
+	query string
+	list  widget.List
+	items []widget.Clickable
+}
+
+const maxSearchResults = 8
+
+type searchPaletteTag *SearchLayer
+
+var searchWidth = unit.Dp(400)
+
+func (layer *SearchLayer) Layout(gtx *Context) {
+	layer.update(gtx)
+	if !layer.Open {
+		return
+	}
+
+	// clicking outside of the palette closes it
+	func() {
+		defer clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops).Pop()
+		event.Op(gtx.Ops, layer)
+	}()
+
+	layout.N.Layout(gtx.Context, func(lgtx layout.Context) layout.Dimensions {
+		return layout.Inset{Top: 32}.Layout(lgtx, func(lgtx layout.Context) layout.Dimensions {
+			lgtx.Constraints.Min.X = min(lgtx.Dp(searchWidth), lgtx.Constraints.Max.X)
+			lgtx.Constraints.Max.X = lgtx.Constraints.Min.X
+			return layer.layoutPalette(gtx, lgtx)
+		})
+	})
+}
+
+func (layer *SearchLayer) update(gtx *Context) {
+	for {
+		e, ok := gtx.Event(
+			key.Filter{Name: "F", Required: key.ModShortcut},
+			key.Filter{Focus: &layer.input, Name: key.NameEscape},
+			key.Filter{Focus: &layer.input, Name: key.NameUpArrow},
+			key.Filter{Focus: &layer.input, Name: key.NameDownArrow},
+			key.Filter{Focus: &layer.input, Name: key.NameReturn},
+			key.Filter{Focus: &layer.input, Name: key.NameEnter},
+			pointer.Filter{Target: layer, Kinds: pointer.Press},
+		)
+		if !ok {
+			break
+		}
+
+		switch ev := e.(type) {
+		case key.Event:
+			if ev.State != key.Press {
+				continue
+			}
+			switch ev.Name {
+			case "F":
+				layer.Open = true
+				layer.input.SetText("")
+				layer.search(gtx, "")
+				gtx.Execute(key.FocusCmd{Tag: &layer.input})
+			case key.NameEscape:
+				layer.close(gtx)
+			case key.NameUpArrow:
+				if layer.Selected > 0 {
+					layer.Selected--
+				}
+				layer.reveal()
+			case key.NameDownArrow:
+				if layer.Selected < len(layer.Results)-1 {
+					layer.Selected++
+				}
+				layer.reveal()
+			case key.NameReturn, key.NameEnter:
+				if layer.Selected < len(layer.Results) {
+					layer.Jump(gtx, layer.Results[layer.Selected].Node)
+				}
+				layer.close(gtx)
+			}
+		case pointer.Event:
+			layer.close(gtx)
+		}
+	}
+
+	if !layer.Open {
+		return
+	}
+
+	for {
+		_, ok := layer.input.Update(gtx.Context)
+		if !ok {
+			break
+		}
+	}
+	if query := layer.input.Text(); query != layer.query {
+		layer.search(gtx, query)
+	}
+
+	for i := range layer.items {
+		if layer.items[i].Clicked(gtx.Context) && i < len(layer.Results) {
+			layer.Jump(gtx, layer.Results[i].Node)
+			layer.close(gtx)
+			break
+		}
+	}
+}
+
+func (layer *SearchLayer) search(gtx *Context, query string) {
+	layer.query = query
+	layer.Results = gtx.Diagram.Search(query)
+	layer.Selected = 0
+}
+
+// reveal scrolls the result list to show the selected result.
+func (layer *SearchLayer) reveal() {
+	pos := layer.list.Position
+	if layer.Selected < pos.First {
+		layer.list.ScrollTo(layer.Selected)
+	} else if pos.Count > 0 && layer.Selected >= pos.First+pos.Count {
+		layer.list.ScrollTo(layer.Selected - pos.Count + 1)
+	}
+}
+
+func (layer *SearchLayer) close(gtx *Context) {
+	layer.Open = false
+	layer.Results = nil
+	gtx.Execute(key.FocusCmd{})
+}
+
+// Jump moves the view to the node and adds it to Diagram.Focus.
+//
+// Groups containing the node are expanded and the zoom is
+// adjusted so that the node fits comfortably in the view.
+func (layer *SearchLayer) Jump(gtx *Context, node *Node) {
+	for group := node.Group; group != nil; group = group.Parent {
+		if group.Collapsed {
+			group.Expand()
+		}
+	}
+
+	layer.Zoom.Center = node.Pos.Add(node.Size.Scale(0.5))
+	for level := range ZoomLevels {
+		zoom := Zoom{Level: level}
+		tr := NewTransform(gtx.Context, &zoom)
+		size := tr.Pt(node.Size).Sub(tr.Pt(Vector{}))
+		if size.X <= gtx.Constraints.Max.X/2 && size.Y <= gtx.Constraints.Max.Y/2 {
+			layer.Zoom.Level = level
+			break
+		}
+	}
+
+	gtx.Diagram.Focus.Include(node)
+	gtx.Execute(op.InvalidateCmd{})
+}
+
+func (layer *SearchLayer) layoutPalette(gtx *Context, lgtx layout.Context) layout.Dimensions {
+	th := gtx.Theme.Theme
+
+	macro := op.Record(lgtx.Ops)
+	dims := layout.UniformInset(8).Layout(lgtx, func(lgtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(lgtx,
+			layout.Rigid(func(lgtx layout.Context) layout.Dimensions {
+				layer.input.SingleLine = true
+				return layout.UniformInset(4).Layout(lgtx,
+					material.Editor(th, &layer.input, "Search nodes and ports").Layout)
+			}),
+			layout.Rigid(func(lgtx layout.Context) layout.Dimensions {
+				return layer.layoutResults(gtx, lgtx)
+			}),
+		)
+	})
+	call := macro.Stop()
+
+	r := image.Rectangle{Max: dims.Size}
+	radius := lgtx.Dp(4)
+	paint.FillShape(lgtx.Ops, gtx.Theme.Background, clip.UniformRRect(r, radius).Op(lgtx.Ops))
+	paint.FillShape(lgtx.Ops, gtx.Theme.Grid, clip.Stroke{
+		Path:  clip.UniformRRect(r, radius).Path(lgtx.Ops),
+		Width: float32(lgtx.Dp(1)),
+	}.Op())
+
+	// block clicks from reaching the layers below
+	area := clip.Rect(r).Push(lgtx.Ops)
+	event.Op(lgtx.Ops, searchPaletteTag(layer))
+	area.Pop()
+
+	call.Add(lgtx.Ops)
+	return dims
+}
+
+func (layer *SearchLayer) layoutResults(gtx *Context, lgtx layout.Context) layout.Dimensions {
+	th := gtx.Theme.Theme
+
+	if len(layer.items) < len(layer.Results) {
+		layer.items = make([]widget.Clickable, len(layer.Results))
+	}
+
+	layer.list.Axis = layout.Vertical
+	lgtx.Constraints.Max.Y = min(lgtx.Constraints.Max.Y, maxSearchResults*lgtx.Dp(th.FingerSize))
+	return material.List(th, &layer.list).Layout(lgtx, len(layer.Results),
+		func(lgtx layout.Context, index int) layout.Dimensions {
+			result := layer.Results[index]
+			return layer.items[index].Layout(lgtx, func(lgtx layout.Context) layout.Dimensions {
+				lgtx.Constraints.Min.X = lgtx.Constraints.Max.X
+
+				macro := op.Record(lgtx.Ops)
+				dims := layout.UniformInset(4).Layout(lgtx, func(lgtx layout.Context) layout.Dimensions {
+					text := result.Text
+					if result.Port {
+						text = nodeName(result.Node) + " · " + result.Text
+					}
+					return material.Body1(th, text).Layout(lgtx)
+				})
+				call := macro.Stop()
+
+				if index == layer.Selected {
+					paint.FillShape(lgtx.Ops, gtx.Theme.Selected.Fill, clip.Rect{Max: dims.Size}.Op())
+				}
+				call.Add(lgtx.Ops)
+				return dims
+			})
+		})
+}
+
+// nodeName returns a short description of the node.
+func nodeName(node *Node) string {
+	if display, ok := node.Display.(Searchable); ok {
+		if texts := display.SearchText(); len(texts) > 0 {
+			return texts[0]
+		}
+	}
+	return "node"
+}
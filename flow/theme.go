@@ -7,9 +7,10 @@ import (
 )
 
 type Theme struct {
-	Node Style
-	Port Style
-	Conn Style
+	Node  Style
+	Port  Style
+	Conn  Style
+	Group Style
 
 	Selected Style
 	Active   Style
@@ -28,9 +29,10 @@ type Style struct {
 
 func NewTheme(th *material.Theme) *Theme {
 	return &Theme{
-		Node: Tango[0],
-		Port: Tango[1],
-		Conn: Tango[3],
+		Node:  Tango[0],
+		Port:  Tango[1],
+		Conn:  Tango[3],
+		Group: Tango[7],
 
 		Selected: Tango[4],
 		Active:   Tango[5],
@@ -90,6 +92,11 @@ var Tango = []Style{
 	},
 }
 
+func WithAlpha(c color.NRGBA, v byte) color.NRGBA {
+	c.A = v
+	return c
+}
+
 func hexRGB(v uint32) color.NRGBA {
 	return color.NRGBA{
 		R: byte(v >> 16),
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"image"
 	"math"
 
 	"gioui.org/layout"
@@ -10,8 +9,9 @@ import (
 )
 
 type Zoom struct {
-	Level  int
-	Center image.Point
+	Level int
+	// Center is the diagram position shown in the middle of the view.
+	Center Vector
 }
 
 func (zoom *Zoom) Multiplier() float32 {
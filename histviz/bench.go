@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReadBenchmarks parses `go test -bench` output and returns a metric with
+// samples for each benchmark that reports the unit. Benchmarks are returned
+// in the order of their first appearance.
+func ReadBenchmarks(r io.Reader, unit string) ([]Metric, error) {
+	type sample struct {
+		name  string
+		value float64
+	}
+	var samples []sample
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		name, value, ok := parseBenchmarkLine(scanner.Text(), unit)
+		if ok {
+			samples = append(samples, sample{name, value})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(samples))
+	for i, s := range samples {
+		names[i] = s.name
+	}
+	suffix := procsSuffix(names)
+
+	var metrics []Metric
+	index := map[string]int{}
+	for _, s := range samples {
+		name := strings.TrimSuffix(s.name, suffix)
+		i, exists := index[name]
+		if !exists {
+			i = len(metrics)
+			index[name] = i
+			metrics = append(metrics, Metric{Label: name})
+		}
+		metrics[i].Samples = append(metrics[i].Samples, s.value)
+	}
+	return metrics, nil
+}
+
+// parseBenchmarkLine parses a line such as:
+//
+//	BenchmarkDecode/small-8   	  261848	      4563 ns/op	    1024 B/op	       3 allocs/op
+//
+// It returns the benchmark name without the "Benchmark" prefix and the
+// measurement with the specified unit.
+func parseBenchmarkLine(line, unit string) (name string, value float64, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+		return "", 0, false
+	}
+	if _, err := strconv.ParseUint(fields[1], 10, 64); err != nil {
+		return "", 0, false
+	}
+
+	name = strings.TrimPrefix(fields[0], "Benchmark")
+	for i := 2; i+1 < len(fields); i += 2 {
+		if fields[i+1] != unit {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return "", 0, false
+		}
+		return name, value, true
+	}
+	return "", 0, false
+}
+
+// procsSuffix returns the GOMAXPROCS suffix, e.g. "-8", when all the names
+// end with it. go test omits the suffix when GOMAXPROCS is 1, hence a
+// suffix that differs between the names is part of the name instead.
+func procsSuffix(names []string) string {
+	suffix := ""
+	for i, name := range names {
+		k := strings.LastIndexByte(name, '-')
+		if k < 0 {
+			return ""
+		}
+		if _, err := strconv.Atoi(name[k+1:]); err != nil {
+			return ""
+		}
+		if i == 0 {
+			suffix = name[k:]
+		} else if name[k:] != suffix {
+			return ""
+		}
+	}
+	return suffix
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadBenchmarks(t *testing.T) {
+	const input = `goos: linux
+goarch: amd64
+pkg: example.com/codec
+BenchmarkDecode/small-8   	  261848	      4563 ns/op	    1024 B/op	       3 allocs/op
+BenchmarkDecode/large-8   	    1000	   1200000 ns/op	  524288 B/op	      12 allocs/op
+BenchmarkDecode/small-8   	  261848	      4600 ns/op	    1024 B/op	       3 allocs/op
+BenchmarkEncode-8       	  500000	      2500.5 ns/op
+BenchmarkThroughput-8   	     100	  10000 ns/op	  50.00 MB/s
+PASS
+ok  	example.com/codec	5.123s
+`
+
+	tests := []struct {
+		unit string
+		want []Metric
+	}{
+		{"ns/op", []Metric{
+			{Label: "Decode/small", Samples: []float64{4563, 4600}},
+			{Label: "Decode/large", Samples: []float64{1200000}},
+			{Label: "Encode", Samples: []float64{2500.5}},
+			{Label: "Throughput", Samples: []float64{10000}},
+		}},
+		{"allocs/op", []Metric{
+			{Label: "Decode/small", Samples: []float64{3, 3}},
+			{Label: "Decode/large", Samples: []float64{12}},
+		}},
+		{"MB/s", []Metric{
+			{Label: "Throughput", Samples: []float64{50}},
+		}},
+		{"unknown/op", nil},
+	}
+
+	for _, test := range tests {
+		got, err := ReadBenchmarks(strings.NewReader(input), test.unit)
+		if err != nil {
+			t.Fatalf("%s: %v", test.unit, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s:\ngot  %+v\nwant %+v", test.unit, got, test.want)
+		}
+	}
+}
+
+func TestReadBenchmarksSingleProc(t *testing.T) {
+	// GOMAXPROCS=1 omits the suffix, so the sizes must stay in the names.
+	const input = `BenchmarkDecode/size-1024 	  261848	      4563 ns/op
+BenchmarkDecode/size-4096 	   60000	     18000 ns/op
+BenchmarkDecode/size-1024 	  261848	      4600 ns/op
+`
+	want := []Metric{
+		{Label: "Decode/size-1024", Samples: []float64{4563, 4600}},
+		{Label: "Decode/size-4096", Samples: []float64{18000}},
+	}
+
+	got, err := ReadBenchmarks(strings.NewReader(input), "ns/op")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestProcsSuffix(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"A-8", "B/x-8"}, "-8"},
+		{[]string{"A/size-1024", "A/size-4096"}, ""},
+		{[]string{"A-8", "B"}, ""},
+		{[]string{"A-x", "B-x"}, ""},
+	}
+
+	for _, test := range tests {
+		if got := procsSuffix(test.names); got != test.want {
+			t.Errorf("%q: got %q, want %q", test.names, got, test.want)
+		}
+	}
+}
+
+func TestParseBenchmarkLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		name  string
+		value float64
+		ok    bool
+	}{
+		{"BenchmarkA-16 100 5 ns/op", "A-16", 5, true},
+		{"BenchmarkA/sub-case-4 100 5 ns/op", "A/sub-case-4", 5, true},
+		{"BenchmarkA/n-x 100 5 ns/op", "A/n-x", 5, true},
+		{"BenchmarkA 100 5 B/op", "", 0, false},
+		{"BenchmarkA --- FAIL", "", 0, false},
+		{"BenchmarkA 100 x ns/op", "", 0, false},
+		{"TestA 100 5 ns/op", "", 0, false},
+	}
+
+	for _, test := range tests {
+		name, value, ok := parseBenchmarkLine(test.line, "ns/op")
+		if name != test.name || value != test.value || ok != test.ok {
+			t.Errorf("%q: got (%q, %v, %v), want (%q, %v, %v)",
+				test.line, name, value, ok, test.name, test.value, test.ok)
+		}
+	}
+}
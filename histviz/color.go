@@ -51,10 +51,10 @@ func RGBALerp(a, b color.NRGBA, p float32) color.NRGBA {
 	ar, ag, ab, aa := RGBAFloat(a)
 	br, bg, bb, ba := RGBAFloat(b)
 	return RGBA(
-		lerpClamp(ar, br, p),
-		lerpClamp(ag, bg, p),
-		lerpClamp(ab, bb, p),
-		lerpClamp(aa, ba, p),
+		lerpClamp(p, ar, br),
+		lerpClamp(p, ag, bg),
+		lerpClamp(p, ab, bb),
+		lerpClamp(p, aa, ba),
 	)
 }
 
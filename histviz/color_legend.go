@@ -1,18 +1,79 @@
 package main
 
 import (
+	"image"
+	"math"
+
 	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
 )
 
+// ColorLegend draws the palette gradient with value labels.
 type ColorLegend struct {
+	Theme   *material.Theme
 	Data    *Data
 	Palette *Palette
-	Active  float64
+	// Active is the highlighted value, NaN hides the marker.
+	Active float64
+	// Format formats the tick labels, defaults to formatValue.
+	Format func(float64) string
 }
 
+var colorLegendWidth = unit.Dp(16)
+
 func (plot *ColorLegend) Layout(gtx layout.Context) layout.Dimensions {
 	gtx.Constraints.Min = gtx.Constraints.Max
 	size := gtx.Constraints.Max
 
+	span := plot.Data.Range
+	bar := image.Rectangle{
+		Min: image.Pt(gtx.Dp(8), gtx.Sp(plotTextSize)),
+		Max: image.Pt(gtx.Dp(8+colorLegendWidth), size.Y-gtx.Sp(plotTextSize)),
+	}
+	if bar.Dy() <= 0 {
+		return layout.Dimensions{Size: size}
+	}
+
+	// position converts palette location to screen, high values at the top
+	position := func(p float64) int {
+		return int(lerp(clamp(p, 0, 1), float64(bar.Max.Y), float64(bar.Min.Y)))
+	}
+
+	step := max(gtx.Dp(2), 1)
+	for y := bar.Min.Y; y < bar.Max.Y; y += step {
+		p := invlerp(float64(y)+float64(step)/2, float64(bar.Max.Y), float64(bar.Min.Y))
+		paint.FillShape(gtx.Ops, plot.Palette.Colormap.At(p), clip.Rect{
+			Min: image.Pt(bar.Min.X, y),
+			Max: image.Pt(bar.Max.X, min(y+step, bar.Max.Y)),
+		}.Op())
+	}
+
+	format := plot.Format
+	if format == nil {
+		format = formatValue
+	}
+	for _, v := range plot.Palette.Ticks(span) {
+		y := position(plot.Palette.Position(v, span))
+		paint.FillShape(gtx.Ops, plotLineColor, clip.Rect{
+			Min: image.Pt(bar.Max.X, y),
+			Max: image.Pt(bar.Max.X+gtx.Dp(4), y+1),
+		}.Op())
+		DrawText(gtx, plot.Theme, image.Pt(bar.Max.X+gtx.Dp(6), y), layout.W, format(v))
+	}
+
+	if !math.IsNaN(plot.Active) {
+		y := position(plot.Palette.Position(plot.Active, span))
+		paint.FillShape(gtx.Ops, plotLineColor, clip.Rect{
+			Min: image.Pt(bar.Min.X-gtx.Dp(4), y-gtx.Dp(1)),
+			Max: image.Pt(bar.Max.X, y+gtx.Dp(1)),
+		}.Op())
+	}
+
+	DrawText(gtx, plot.Theme, image.Pt(bar.Min.X, size.Y), layout.SW,
+		plot.Palette.Scale.String()+" · "+plot.Palette.Colormap.Name)
+
 	return layout.Dimensions{Size: size}
 }
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// Comparison contains the relative change between two datasets.
+//
+// Base, Head and Delta contain the same metrics and percentiles in the
+// same order, i.e. Delta.Metrics[i].Values[k] is the change between
+// Base.Metrics[i].Values[k] and Head.Metrics[i].Values[k].
+type Comparison struct {
+	Base, Head *Data
+	// Delta contains the relative change (head - base) / base.
+	Delta *Data
+	// PValues contains the Mann-Whitney U-test p-value for each metric,
+	// NaN when there are not enough samples.
+	PValues []float64
+}
+
+// significanceLevel is the p-value below which the change is considered
+// significant.
+const significanceLevel = 0.05
+
+// Compare matches the metrics and percentiles in base and head by their
+// labels and calculates the relative change between them.
+func Compare(base, head *Data) (*Comparison, error) {
+	basePercentiles := map[string]int{}
+	for k, p := range base.Percentiles {
+		basePercentiles[p.Label] = k
+	}
+	var percentiles []Percentile
+	var baseRows, headRows []int
+	for k, p := range head.Percentiles {
+		if b, ok := basePercentiles[p.Label]; ok {
+			percentiles = append(percentiles, p)
+			baseRows = append(baseRows, b)
+			headRows = append(headRows, k)
+		}
+	}
+	if len(percentiles) == 0 {
+		return nil, errors.New("no matching percentiles")
+	}
+
+	baseMetrics := map[string]*Metric{}
+	for i := range base.Metrics {
+		baseMetrics[base.Metrics[i].Label] = &base.Metrics[i]
+	}
+
+	// pick selects the rows from the values.
+	pick := func(values []float64, rows []int) []float64 {
+		r := make([]float64, len(rows))
+		for i, row := range rows {
+			r[i] = math.NaN()
+			if row < len(values) {
+				r[i] = values[row]
+			}
+		}
+		return r
+	}
+
+	c := &Comparison{
+		Base:  &Data{Percentiles: percentiles},
+		Head:  &Data{Percentiles: percentiles},
+		Delta: &Data{Percentiles: percentiles},
+	}
+	for i := range head.Metrics {
+		headMetric := &head.Metrics[i]
+		baseMetric, ok := baseMetrics[headMetric.Label]
+		if !ok {
+			continue
+		}
+
+		b := Metric{Label: baseMetric.Label, Values: pick(baseMetric.Values, baseRows), Samples: baseMetric.Samples}
+		h := Metric{Label: headMetric.Label, Values: pick(headMetric.Values, headRows), Samples: headMetric.Samples}
+		d := Metric{Label: headMetric.Label, Values: make([]float64, len(percentiles))}
+		for k := range d.Values {
+			d.Values[k] = (h.Values[k] - b.Values[k]) / b.Values[k]
+			if math.IsInf(d.Values[k], 0) {
+				d.Values[k] = math.NaN()
+			}
+		}
+
+		c.Base.Metrics = append(c.Base.Metrics, b)
+		c.Head.Metrics = append(c.Head.Metrics, h)
+		c.Delta.Metrics = append(c.Delta.Metrics, d)
+		c.PValues = append(c.PValues, mannWhitneyU(b.Samples, h.Samples))
+	}
+	if len(c.Delta.Metrics) == 0 {
+		return nil, errors.New("no matching metrics")
+	}
+
+	c.Base.UpdateRange()
+	c.Head.UpdateRange()
+	c.Delta.UpdateRange()
+
+	// keep no change in the middle of the range
+	extent := max(math.Abs(c.Delta.Range.Min), math.Abs(c.Delta.Range.Max))
+	if extent == 0 {
+		extent = 1
+	}
+	c.Delta.Range = Range{Min: -extent, Max: extent}
+
+	return c, nil
+}
+
+// Range returns the range covering both base and head values.
+func (c *Comparison) Range() Range {
+	return Range{
+		Min: min(c.Base.Range.Min, c.Head.Range.Min),
+		Max: max(c.Base.Range.Max, c.Head.Range.Max),
+	}
+}
+
+// Significant reports whether the change in metric is statistically significant.
+// ok is false when there are not enough samples to tell.
+func (c *Comparison) Significant(metric int) (significant, ok bool) {
+	p := c.PValues[metric]
+	if math.IsNaN(p) {
+		return false, false
+	}
+	return p < significanceLevel, true
+}
+
+// Describe returns a description of the change in the cell,
+// where at.X is the metric and at.Y is the percentile.
+func (c *Comparison) Describe(at image.Point) []string {
+	base := c.Base.Metrics[at.X]
+	head := c.Head.Metrics[at.X]
+	delta := c.Delta.Metrics[at.X]
+
+	lines := []string{
+		head.Label + " " + c.Delta.Percentiles[at.Y].Label,
+		"base  " + formatValue(base.Values[at.Y]),
+		"head  " + formatValue(head.Values[at.Y]),
+		"delta " + formatPercent(delta.Values[at.Y]),
+	}
+
+	p := c.PValues[at.X]
+	if !math.IsNaN(p) {
+		hint := "significant"
+		if p >= significanceLevel {
+			hint = "not significant"
+		}
+		lines = append(lines, fmt.Sprintf("p=%.3f n=%d+%d %s", p, len(base.Samples), len(head.Samples), hint))
+	}
+	return lines
+}
+
+func formatPercent(v float64) string {
+	if math.IsNaN(v) {
+		return "?"
+	}
+	s := strconv.FormatFloat(v*100, 'f', 1, 64) + "%"
+	if v > 0 {
+		s = "+" + s
+	}
+	return s
+}
+
+// mannWhitneyU returns the two-sided p-value of the Mann-Whitney U-test
+// using the normal approximation with tie correction.
+func mannWhitneyU(a, b []float64) float64 {
+	n1, n2 := len(a), len(b)
+	if n1 < 2 || n2 < 2 {
+		return math.NaN()
+	}
+
+	type sample struct {
+		value float64
+		first bool
+	}
+	all := make([]sample, 0, n1+n2)
+	for _, v := range a {
+		all = append(all, sample{value: v, first: true})
+	}
+	for _, v := range b {
+		all = append(all, sample{value: v})
+	}
+	slices.SortFunc(all, func(x, y sample) int { return cmp.Compare(x.value, y.value) })
+
+	// assign average ranks to ties
+	var rankSum, ties float64
+	for i := 0; i < len(all); {
+		k := i + 1
+		for k < len(all) && all[k].value == all[i].value {
+			k++
+		}
+		rank := float64(i+k+1) / 2
+		for _, s := range all[i:k] {
+			if s.first {
+				rankSum += rank
+			}
+		}
+		t := float64(k - i)
+		ties += t*t*t - t
+		i = k
+	}
+
+	n := float64(n1 + n2)
+	u := rankSum - float64(n1*(n1+1))/2
+	mu := float64(n1*n2) / 2
+	sigma := math.Sqrt(float64(n1*n2) / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sigma == 0 {
+		return 1
+	}
+
+	z := max(math.Abs(u-mu)-0.5, 0) / sigma
+	return math.Erfc(z / math.Sqrt2)
+}
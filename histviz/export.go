@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gioui.org/text"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Chart is a static rendering of the percentile grid with axes and a
+// color legend, used for exporting.
+type Chart struct {
+	Data       *Data
+	Palette    *Palette
+	Comparison *Comparison
+	// Columns are the metric indices to draw, nil draws all of them.
+	Columns []int
+	Size    image.Point
+}
+
+// Canvas is a drawing target for Chart.
+type Canvas interface {
+	// Rect fills r with col.
+	Rect(r image.Rectangle, col color.NRGBA)
+	// Text draws txt vertically centered at pos.
+	Text(pos image.Point, align text.Alignment, txt string, col color.NRGBA)
+}
+
+const (
+	chartMargin      = 8
+	chartRowAxis     = 80
+	chartColumnAxis  = 20
+	chartLegendWidth = 100
+	chartTextSize    = 12
+)
+
+// Draw draws the chart to the canvas.
+func (chart *Chart) Draw(canvas Canvas) {
+	data := chart.Data
+	columns := chart.Columns
+	if columns == nil {
+		for i := range data.Metrics {
+			columns = append(columns, i)
+		}
+	}
+
+	canvas.Rect(image.Rectangle{Max: chart.Size}, White)
+	if len(columns) == 0 || len(data.Percentiles) == 0 {
+		return
+	}
+
+	grid := image.Rectangle{
+		Min: image.Pt(chartMargin+chartRowAxis, chartMargin+chartColumnAxis),
+		Max: image.Pt(chart.Size.X-chartMargin-chartLegendWidth, chart.Size.Y-chartMargin),
+	}
+	cellSize := image.Pt(grid.Dx()/len(columns), grid.Dy()/len(data.Percentiles))
+	if cellSize.X <= 0 || cellSize.Y <= 0 {
+		return
+	}
+
+	// draw cells
+	for x, index := range columns {
+		for y, value := range data.Metrics[index].Values {
+			min := grid.Min.Add(mulpoint(cellSize, image.Pt(x, y)))
+			canvas.Rect(image.Rectangle{Min: min, Max: min.Add(cellSize)}, chart.Palette.Color(value, data.Range))
+		}
+	}
+
+	// draw column labels, skipping the ones that would overlap
+	nextFree := 0
+	for x, index := range columns {
+		label := data.Metrics[index].Label
+		if chart.Comparison != nil {
+			if significant, ok := chart.Comparison.Significant(index); ok && !significant {
+				label = "~ " + label
+			}
+		}
+
+		center := grid.Min.X + x*cellSize.X + cellSize.X/2
+		width := len(label) * chartTextSize * 6 / 10
+		if center-width/2 < nextFree {
+			continue
+		}
+		nextFree = center + width/2 + chartTextSize
+		canvas.Text(image.Pt(center, grid.Min.Y-chartColumnAxis/2), text.Middle, label, Black)
+	}
+
+	// draw row labels
+	for y, p := range data.Percentiles {
+		pos := image.Pt(grid.Min.X-chartMargin, grid.Min.Y+y*cellSize.Y+cellSize.Y/2)
+		canvas.Text(pos, text.End, p.Label, Black)
+	}
+
+	// draw legend
+	bar := image.Rectangle{
+		Min: image.Pt(grid.Max.X+2*chartMargin, grid.Min.Y),
+		Max: image.Pt(grid.Max.X+2*chartMargin+16, grid.Max.Y),
+	}
+	const steps = 64
+	for i := range steps {
+		y0 := bar.Max.Y - bar.Dy()*i/steps
+		y1 := bar.Max.Y - bar.Dy()*(i+1)/steps
+		p := (float64(i) + 0.5) / steps
+		canvas.Rect(image.Rect(bar.Min.X, y1, bar.Max.X, y0), chart.Palette.Colormap.At(p))
+	}
+
+	format := formatValue
+	if chart.Comparison != nil {
+		format = formatPercent
+	}
+	for _, v := range chart.Palette.Ticks(data.Range) {
+		p := chart.Palette.Position(v, data.Range)
+		y := int(lerp(clamp(p, 0, 1), float64(bar.Max.Y), float64(bar.Min.Y)))
+		canvas.Rect(image.Rect(bar.Max.X, y, bar.Max.X+4, y+1), Black)
+		canvas.Text(image.Pt(bar.Max.X+6, y), text.Start, format(v), Black)
+	}
+}
+
+// Export writes the chart to the file, the format is determined by the
+// extension: .svg, .png or .csv.
+func (chart *Chart) Export(path string) error {
+	// check the format first, to avoid leaving behind an empty file
+	var write func(w io.Writer) error
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".svg":
+		write = chart.WriteSVG
+	case ".png":
+		write = chart.WritePNG
+	case ".csv":
+		write = chart.Data.WriteCSV
+		if chart.Comparison != nil {
+			write = chart.Comparison.WriteCSV
+		}
+	default:
+		return fmt.Errorf("unknown export format %q, expected .svg, .png or .csv", ext)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	err = write(w)
+
+	if flushErr := w.Flush(); err == nil {
+		err = flushErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// WriteSVG writes the chart as SVG.
+func (chart *Chart) WriteSVG(w io.Writer) error {
+	canvas := &svgCanvas{}
+	fmt.Fprintf(&canvas.body, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %[1]d %[2]d" font-family="sans-serif" font-size="%d">`+"\n",
+		chart.Size.X, chart.Size.Y, chartTextSize)
+	chart.Draw(canvas)
+	canvas.body.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, canvas.body.String())
+	return err
+}
+
+// WritePNG writes the chart as PNG.
+func (chart *Chart) WritePNG(w io.Writer) error {
+	face, err := chartFace()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = face.Close() }()
+
+	canvas := &rasterCanvas{
+		img:  image.NewRGBA(image.Rectangle{Max: chart.Size}),
+		face: face,
+	}
+	chart.Draw(canvas)
+	return png.Encode(w, canvas.img)
+}
+
+// svgCanvas draws the chart as SVG elements.
+type svgCanvas struct {
+	body strings.Builder
+}
+
+func (canvas *svgCanvas) Rect(r image.Rectangle, col color.NRGBA) {
+	fmt.Fprintf(&canvas.body, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+		r.Min.X, r.Min.Y, r.Dx(), r.Dy(), svgColor(col))
+}
+
+func (canvas *svgCanvas) Text(pos image.Point, align text.Alignment, txt string, col color.NRGBA) {
+	anchor := "start"
+	switch align {
+	case text.Middle:
+		anchor = "middle"
+	case text.End:
+		anchor = "end"
+	}
+	fmt.Fprintf(&canvas.body, `<text x="%d" y="%d" text-anchor="%s" dominant-baseline="central" fill="%s">%s</text>`+"\n",
+		pos.X, pos.Y, anchor, svgColor(col), html.EscapeString(txt))
+}
+
+func svgColor(col color.NRGBA) string {
+	if col.A == 0xFF {
+		return fmt.Sprintf("#%02x%02x%02x", col.R, col.G, col.B)
+	}
+	return fmt.Sprintf("rgba(%d,%d,%d,%.3f)", col.R, col.G, col.B, float64(col.A)/0xFF)
+}
+
+// rasterCanvas draws the chart to an image.
+type rasterCanvas struct {
+	img  *image.RGBA
+	face font.Face
+}
+
+func (canvas *rasterCanvas) Rect(r image.Rectangle, col color.NRGBA) {
+	draw.Draw(canvas.img, r, image.NewUniform(col), image.Point{}, draw.Over)
+}
+
+func (canvas *rasterCanvas) Text(pos image.Point, align text.Alignment, txt string, col color.NRGBA) {
+	drawer := font.Drawer{
+		Dst:  canvas.img,
+		Src:  image.NewUniform(col),
+		Face: canvas.face,
+	}
+
+	width := drawer.MeasureString(txt)
+	x := fixed.I(pos.X)
+	switch align {
+	case text.Middle:
+		x -= width / 2
+	case text.End:
+		x -= width
+	}
+
+	metrics := canvas.face.Metrics()
+	drawer.Dot = fixed.Point26_6{
+		X: x,
+		Y: fixed.I(pos.Y) + (metrics.Ascent-metrics.Descent)/2,
+	}
+	drawer.DrawString(txt)
+}
+
+// chartFace loads the font used for PNG export.
+func chartFace() (font.Face, error) {
+	f, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, err
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    chartTextSize,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
+
+// WriteCSV writes the percentile values with a row for each metric.
+func (data *Data) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"metric"}
+	for _, p := range data.Percentiles {
+		header = append(header, p.Label)
+	}
+	_ = cw.Write(header)
+
+	for _, metric := range data.Metrics {
+		row := []string{metric.Label}
+		for _, v := range metric.Values {
+			row = append(row, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		_ = cw.Write(row)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCSV writes base, head and relative change with a row for each
+// metric and percentile.
+func (c *Comparison) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"metric", "percentile", "base", "head", "delta", "p-value"})
+
+	for i := range c.Delta.Metrics {
+		for k, p := range c.Delta.Percentiles {
+			_ = cw.Write([]string{
+				c.Delta.Metrics[i].Label,
+				p.Label,
+				strconv.FormatFloat(c.Base.Metrics[i].Values[k], 'g', -1, 64),
+				strconv.FormatFloat(c.Head.Metrics[i].Values[k], 'g', -1, 64),
+				strconv.FormatFloat(c.Delta.Metrics[i].Values[k], 'g', -1, 64),
+				strconv.FormatFloat(c.PValues[i], 'g', -1, 64),
+			})
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ReadHdrLog parses HdrHistogram interval log and creates a metric for
+// each tag by merging all the intervals, e.g.
+//
+//	#[StartTime: 1441812279.474 (seconds since epoch), Wed Sep 09 08:24:39 PDT 2015]
+//	"StartTimestamp","Interval_Length","Interval_Max","Interval_Compressed_Histogram"
+//	Tag=get,0.127,1.007,2.769,HISTFAAAAEV42pNpmSzMwMCgyAABTBDKT4GBgYXBgYGFgYGB...
+//
+// The percentiles are interpolated from the histogram bucket boundaries.
+// Metrics are returned in the order of their first appearance.
+func ReadHdrLog(r io.Reader, percentiles []Percentile) ([]Metric, error) {
+	type span struct{ lower, upper float64 }
+	type tagged struct {
+		label  string
+		counts map[span]float64
+	}
+
+	var all []*tagged
+	index := map[string]*tagged{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, `"`) {
+			continue
+		}
+
+		label := "all"
+		if tag, ok := strings.CutPrefix(line, "Tag="); ok {
+			label, line, _ = strings.Cut(tag, ",")
+		}
+
+		fields := strings.Split(line, ",")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("line %d: expected 4 fields, got %d", lineNumber, len(fields))
+		}
+
+		buckets, err := decodeHdrHistogram(fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		t, ok := index[label]
+		if !ok {
+			t = &tagged{label: label, counts: map[span]float64{}}
+			index[label] = t
+			all = append(all, t)
+		}
+		for _, b := range buckets {
+			t.counts[span{b.Lower, b.Upper}] += b.Count
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var metrics []Metric
+	for _, t := range all {
+		buckets := make([]Bucket, 0, len(t.counts))
+		for s, count := range t.counts {
+			buckets = append(buckets, Bucket{Lower: s.lower, Upper: s.upper, Count: count})
+		}
+		sort.Slice(buckets, func(i, k int) bool { return buckets[i].Lower < buckets[k].Lower })
+		metrics = append(metrics, HistogramMetric(t.label, buckets, percentiles))
+	}
+	return metrics, nil
+}
+
+const (
+	hdrEncodingCookieV2           = 0x1c849303
+	hdrCompressedEncodingCookieV2 = 0x1c849304
+)
+
+// hdrHeader is the header of V2 encoded histogram.
+type hdrHeader struct {
+	Cookie                              int32
+	PayloadLength                       int32
+	NormalizingIndexOffset              int32
+	SignificantValueDigits              int32
+	LowestTrackableValue                int64
+	HighestTrackableValue               int64
+	IntegerToDoubleValueConversionRatio float64
+}
+
+// decodeHdrHistogram decodes a base64 encoded compressed V2 histogram
+// into buckets with non-zero counts.
+func decodeHdrHistogram(encoded string) ([]Bucket, error) {
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	if len(compressed) < 8 {
+		return nil, errors.New("histogram too short")
+	}
+	if cookie := binary.BigEndian.Uint32(compressed); cookie&^0xf0 != hdrCompressedEncodingCookieV2 {
+		return nil, fmt.Errorf("unsupported histogram encoding %x", cookie)
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(compressed[8:]))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = zr.Close() }()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	var header hdrHeader
+	if err := binary.Read(bytes.NewReader(data), binary.BigEndian, &header); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	if header.Cookie&^0xf0 != hdrEncodingCookieV2 {
+		return nil, fmt.Errorf("unsupported histogram encoding %x", header.Cookie)
+	}
+	if header.SignificantValueDigits < 0 || header.SignificantValueDigits > 5 || header.LowestTrackableValue < 1 {
+		return nil, errors.New("invalid histogram parameters")
+	}
+
+	payload := data[binary.Size(header):]
+	if int(header.PayloadLength) < len(payload) {
+		payload = payload[:header.PayloadLength]
+	}
+
+	layout := newHdrLayout(header)
+
+	var buckets []Bucket
+	index := int64(0)
+	for len(payload) > 0 {
+		count, n, err := decodeZigZag(payload)
+		if err != nil {
+			return nil, err
+		}
+		payload = payload[n:]
+
+		if count < 0 {
+			index += -count
+			continue
+		}
+		if count > 0 {
+			lower, upper := layout.bucket(index)
+			buckets = append(buckets, Bucket{
+				Lower: lower * header.IntegerToDoubleValueConversionRatio,
+				Upper: upper * header.IntegerToDoubleValueConversionRatio,
+				Count: float64(count),
+			})
+		}
+		index++
+	}
+	return buckets, nil
+}
+
+// hdrLayout describes how HdrHistogram maps count indices to values.
+type hdrLayout struct {
+	unitMagnitude               int64
+	subBucketHalfCountMagnitude int64
+	subBucketHalfCount          int64
+}
+
+func newHdrLayout(header hdrHeader) hdrLayout {
+	largestSingleUnit := 2 * math.Pow10(int(header.SignificantValueDigits))
+	subBucketCountMagnitude := int64(math.Ceil(math.Log2(largestSingleUnit)))
+	subBucketHalfCountMagnitude := max(subBucketCountMagnitude, 1) - 1
+
+	return hdrLayout{
+		unitMagnitude:               int64(math.Floor(math.Log2(float64(header.LowestTrackableValue)))),
+		subBucketHalfCountMagnitude: subBucketHalfCountMagnitude,
+		subBucketHalfCount:          1 << subBucketHalfCountMagnitude,
+	}
+}
+
+// bucket returns the range of values counted at index.
+func (layout hdrLayout) bucket(index int64) (lower, upper float64) {
+	bucketIndex := (index >> layout.subBucketHalfCountMagnitude) - 1
+	subBucketIndex := (index & (layout.subBucketHalfCount - 1)) + layout.subBucketHalfCount
+	if bucketIndex < 0 {
+		subBucketIndex -= layout.subBucketHalfCount
+		bucketIndex = 0
+	}
+
+	shift := bucketIndex + layout.unitMagnitude
+	value := math.Ldexp(float64(subBucketIndex), int(shift))
+	size := math.Ldexp(1, int(shift))
+	return value, value + size
+}
+
+// decodeZigZag decodes a ZigZag LEB128-64b9B encoded integer.
+func decodeZigZag(buf []byte) (value int64, n int, err error) {
+	var v uint64
+	for shift := uint(0); ; shift += 7 {
+		if n >= len(buf) {
+			return 0, 0, errors.New("truncated histogram payload")
+		}
+		b := uint64(buf[n])
+		n++
+		if shift == 56 {
+			v |= b << shift
+			break
+		}
+		v |= (b & 0x7f) << shift
+		if b < 0x80 {
+			break
+		}
+	}
+	return int64(v>>1) ^ -int64(v&1), n, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// hdrVector is the V2 compressed encoding of a histogram created by the
+// reference implementation github.com/HdrHistogram/hdrhistogram-go:
+//
+//	h := hdrhistogram.New(1, 3600000, 3)
+//	for _, v := range []int64{1, 2, 2, 1000, 1000, 1000, 5000, 123456} {
+//		h.RecordValue(v)
+//	}
+//	h.Encode(hdrhistogram.V2CompressedEncodingCookieBase)
+const hdrVector = "HISTFAAAADh42pJpmSzMwMDAw8DAwMjAwMDMwMDAAGUzMJi9a7D/wMDAwMDAwMDEcpKf7aMy02kvJsAAjykHPA=="
+
+func TestDecodeHdrHistogram(t *testing.T) {
+	got, err := decodeHdrHistogram(hdrVector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Bucket{
+		{Lower: 1, Upper: 2, Count: 1},
+		{Lower: 2, Upper: 3, Count: 2},
+		{Lower: 1000, Upper: 1001, Count: 3},
+		{Lower: 5000, Upper: 5004, Count: 1},
+		{Lower: 123456, Upper: 123520, Count: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %v\nwant %v", got, want)
+	}
+}
+
+func TestDecodeHdrHistogramInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"not base64!",
+		"AAAAAAAAAAA=",                      // wrong cookie
+		hdrVector[:len(hdrVector)/2] + "==", // truncated
+	}
+	for _, encoded := range tests {
+		if _, err := decodeHdrHistogram(encoded); err == nil {
+			t.Errorf("%q: expected an error", encoded)
+		}
+	}
+}
+
+func TestReadHdrLog(t *testing.T) {
+	input := strings.Join([]string{
+		"#[StartTime: 1441812279.474 (seconds since epoch), Wed Sep 09 08:24:39 PDT 2015]",
+		`"StartTimestamp","Interval_Length","Interval_Max","Interval_Compressed_Histogram"`,
+		"Tag=get,0.127,1.007,123.456," + hdrVector,
+		"0.127,1.007,123.456," + hdrVector,
+		"Tag=get,1.134,1.000,123.456," + hdrVector,
+		"",
+	}, "\n")
+
+	percentiles := []Percentile{{Label: "p0", Value: 0}, {Label: "p100", Value: 1}}
+	metrics, err := ReadHdrLog(strings.NewReader(input), percentiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(metrics) != 2 || metrics[0].Label != "get" || metrics[1].Label != "all" {
+		t.Fatalf("unexpected metrics %+v", metrics)
+	}
+	for _, metric := range metrics {
+		if len(metric.Values) != 2 || metric.Values[0] < 1 || metric.Values[1] > 123520 || metric.Values[0] > metric.Values[1] {
+			t.Errorf("%s: unexpected values %v", metric.Label, metric.Values)
+		}
+	}
+}
+
+func TestReadHdrLogInvalid(t *testing.T) {
+	tests := []string{
+		"0.127,1.007," + hdrVector,
+		"0.127,1.007,123.456,invalid",
+	}
+	for _, input := range tests {
+		if _, err := ReadHdrLog(strings.NewReader(input), DefaultPercentiles()); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestDecodeZigZag(t *testing.T) {
+	tests := []struct {
+		buf   []byte
+		value int64
+		n     int
+	}{
+		{[]byte{0x00}, 0, 1},
+		{[]byte{0x01}, -1, 1},
+		{[]byte{0x02}, 1, 1},
+		{[]byte{0x7f}, -64, 1},
+		{[]byte{0x80, 0x01}, 64, 2},
+		{[]byte{0xac, 0x02, 0xff}, 150, 2},
+		{[]byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, 1<<63 - 1, 9},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, -1 << 63, 9},
+	}
+	for _, test := range tests {
+		value, n, err := decodeZigZag(test.buf)
+		if err != nil || value != test.value || n != test.n {
+			t.Errorf("%x: got (%v, %v, %v), want (%v, %v)", test.buf, value, n, err, test.value, test.n)
+		}
+	}
+
+	if _, _, err := decodeZigZag([]byte{0x80}); err == nil {
+		t.Errorf("expected an error for truncated input")
+	}
+}
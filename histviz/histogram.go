@@ -0,0 +1,56 @@
+package main
+
+import (
+	"math"
+)
+
+// Bucket is a histogram bucket containing Count values in the range [Lower, Upper].
+type Bucket struct {
+	Lower, Upper float64
+	Count        float64
+}
+
+// HistogramMetric creates a metric by interpolating percentiles from the buckets.
+func HistogramMetric(label string, buckets []Bucket, percentiles []Percentile) Metric {
+	metric := Metric{
+		Label:  label,
+		Values: make([]float64, len(percentiles)),
+	}
+	for i, p := range percentiles {
+		metric.Values[i] = bucketQuantile(buckets, p.Value)
+	}
+	return metric
+}
+
+// bucketQuantile returns the p-th quantile from buckets sorted by their
+// bounds, assuming the values are uniformly distributed within a bucket.
+func bucketQuantile(buckets []Bucket, p float64) float64 {
+	var total float64
+	for _, b := range buckets {
+		total += b.Count
+	}
+	if total <= 0 {
+		return math.NaN()
+	}
+
+	rank := p * total
+	var cumulative float64
+	for _, b := range buckets {
+		if b.Count <= 0 {
+			continue
+		}
+		if cumulative+b.Count >= rank {
+			if math.IsInf(b.Upper, 1) {
+				return b.Lower
+			}
+			return lerp((rank-cumulative)/b.Count, b.Lower, b.Upper)
+		}
+		cumulative += b.Count
+	}
+
+	last := buckets[len(buckets)-1]
+	if math.IsInf(last.Upper, 1) {
+		return last.Lower
+	}
+	return last.Upper
+}
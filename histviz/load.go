@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Formats lists the supported input formats.
+var Formats = []string{"auto", "bench", "hdr", "prom"}
+
+// Load loads metrics from the files.
+//
+// The format is one of Formats, "auto" detects it from the file name and content:
+//
+//	bench - `go test -bench` output, the measurements in unit are used
+//	hdr   - HdrHistogram interval log, one metric per tag
+//	prom  - Prometheus text exposition, one metric per histogram label set
+//
+// When there are multiple files, the metric labels are prefixed with
+// the file name, similarly to how benchstat compares inputs.
+// Path "-" reads from stdin.
+func Load(paths []string, format, unit string) (*Data, error) {
+	percentiles := DefaultPercentiles()
+
+	var metrics []Metric
+	for _, path := range paths {
+		var prefix string
+		if len(paths) > 1 {
+			prefix = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) + "/"
+		}
+
+		content, err := readInput(path)
+		if err != nil {
+			return nil, err
+		}
+
+		fileFormat := format
+		if fileFormat == "auto" {
+			fileFormat = detectFormat(path, content)
+		}
+
+		var ms []Metric
+		switch fileFormat {
+		case "bench":
+			ms, err = ReadBenchmarks(bytes.NewReader(content), unit)
+		case "hdr":
+			ms, err = ReadHdrLog(bytes.NewReader(content), percentiles)
+		case "prom":
+			ms, err = ReadPrometheus(bytes.NewReader(content), percentiles)
+		default:
+			return nil, fmt.Errorf("unknown format %q", fileFormat)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		for _, m := range ms {
+			m.Label = prefix + m.Label
+			metrics = append(metrics, m)
+		}
+	}
+
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("no metrics found")
+	}
+
+	return NewData(percentiles, metrics), nil
+}
+
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// detectFormat guesses the format of the content.
+func detectFormat(path string, content []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".hlog":
+		return "hdr"
+	case ".prom":
+		return "prom"
+	}
+
+	switch {
+	case bytes.Contains(content, []byte("HISTF")), bytes.Contains(content, []byte("#[StartTime:")):
+		return "hdr"
+	case bytes.Contains(content, []byte("_bucket{")), bytes.Contains(content, []byte("# TYPE ")):
+		return "prom"
+	default:
+		return "bench"
+	}
+}
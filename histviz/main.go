@@ -1,18 +1,165 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"image"
+	"math"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"gioui.org/app"
+	"gioui.org/io/key"
 	"gioui.org/layout"
 	"gioui.org/op"
+	"gioui.org/widget"
 	"gioui.org/widget/material"
 )
 
 func main() {
+	unit := flag.String("unit", "ns/op", "benchmark `unit` to visualize")
+	format := flag.String("format", "auto", "input `format`: "+strings.Join(Formats, ", "))
+	scale := flag.String("scale", "log", "color `scale`: "+strings.Join(scaleNames, ", "))
+	colormap := flag.String("colormap", Viridis.Name, "`colormap` name, see the list below")
+	base := flag.String("base", "", "compare against `files` (comma separated) loaded as the baseline")
+	export := flag.String("export", "", "write the chart to `files` (comma separated .svg, .png or .csv) without opening a window")
+	size := flag.String("size", "1200x600", "exported image `size`")
+	streaming := flag.Bool("stream", false, "read `[label] value` sample lines from stdin and show the percentiles live")
+	listen := flag.String("listen", "", "read streamed samples from connections to `address` (host:port or unix socket path)")
+	window := flag.Duration("window", 10*time.Second, "sliding `duration` for streamed percentiles")
+	fps := flag.Int("fps", 10, "maximum `rate` of updating the streamed percentiles")
+	baseline := flag.Float64("baseline", math.NaN(), "center `value` for the diverging colormap")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: histviz [flags] [file ...]")
+		fmt.Fprintln(flag.CommandLine.Output(), "Visualizes `go test -bench -count=N` output, HdrHistogram logs")
+		fmt.Fprintln(flag.CommandLine.Output(), "and Prometheus histograms, use - for stdin.")
+		fmt.Fprintln(flag.CommandLine.Output(), "With -base the relative change from the baseline is shown.")
+		fmt.Fprintln(flag.CommandLine.Output(), "With -stream or -listen the percentiles are calculated from live samples.")
+		flag.PrintDefaults()
+		fmt.Fprintln(flag.CommandLine.Output(), "colormaps:")
+		for _, colormap := range Colormaps {
+			fmt.Fprintln(flag.CommandLine.Output(), "  "+colormap.Name)
+		}
+		fmt.Fprintln(flag.CommandLine.Output(), "Press S to change the scale and C to change the colormap.")
+		fmt.Fprintln(flag.CommandLine.Output(), "Click a percentile to sort the metrics by it.")
+		fmt.Fprintln(flag.CommandLine.Output(), "Press Ctrl+E to export histviz.svg, histviz.png and histviz.csv.")
+	}
+	flag.Parse()
+
+	palette := NewPalette()
+	palette.Baseline = *baseline
+	var err error
+	if palette.Scale, err = ParseScale(*scale); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if palette.Colormap, err = ParseColormap(*colormap); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var stream *Stream
+	if *streaming || *listen != "" {
+		if flag.NArg() > 0 || *base != "" {
+			fmt.Fprintln(os.Stderr, "streaming cannot be combined with files or -base")
+			os.Exit(2)
+		}
+		stream = NewStream(DefaultPercentiles(), *window)
+	}
+
 	data := RandomData()
-	ui := NewUI(data)
+	if stream != nil {
+		data = &Data{Percentiles: stream.Percentiles}
+	} else if flag.NArg() > 0 {
+		data, err = Load(flag.Args(), *format, *unit)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	var comparison *Comparison
+	if *base != "" {
+		baseData, err := Load(strings.Split(*base, ","), *format, *unit)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		comparison, err = Compare(baseData, data)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		// show the change with a diverging palette, unless configured otherwise
+		explicit := map[string]bool{}
+		flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+		if !explicit["scale"] {
+			palette.Scale = ScaleLinear
+		}
+		if !explicit["colormap"] {
+			palette.Colormap = Diverging
+		}
+		if !explicit["baseline"] {
+			palette.Baseline = 0
+		}
+	}
+
+	var exportSize image.Point
+	if _, err := fmt.Sscanf(*size, "%dx%d", &exportSize.X, &exportSize.Y); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid size %q: %v\n", *size, err)
+		os.Exit(2)
+	}
+	if exportSize.X <= 0 || exportSize.Y <= 0 {
+		fmt.Fprintf(os.Stderr, "invalid size %q: must be positive\n", *size)
+		os.Exit(2)
+	}
+
+	ui := NewUI(data, palette)
+	ui.ExportSize = exportSize
+	if stream != nil {
+		ui.Stream = stream
+		ui.RefreshInterval = time.Second / time.Duration(max(*fps, 1))
+
+		if *listen != "" {
+			go func() {
+				if err := stream.Listen(*listen); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(1)
+				}
+			}()
+		}
+		if *streaming {
+			read := func() {
+				if err := stream.Read(os.Stdin); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+				}
+			}
+			if *export != "" {
+				// export the percentiles after reading all the samples
+				read()
+				ui.refresh(time.Now())
+			} else {
+				go read()
+			}
+		}
+	}
+	if comparison != nil {
+		ui.SetComparison(comparison)
+	}
+
+	if *export != "" {
+		chart := ui.Chart()
+		for _, path := range strings.Split(*export, ",") {
+			if err := chart.Export(path); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+		return
+	}
 
 	go func() {
 		var w app.Window
@@ -37,10 +184,20 @@ type UI struct {
 	percentilePlot *PercentilePlot
 	trendPlot      *TrendPlot
 	colorLegend    *ColorLegend
+
+	filter widget.Editor
+
+	// ExportSize is the size of the exported images.
+	ExportSize image.Point
+
+	// Stream, when set, is used to update data at RefreshInterval.
+	Stream          *Stream
+	RefreshInterval time.Duration
+	lastRefresh     time.Time
 }
 
-func NewUI(data *Data) *UI {
-	palette := &Palette{}
+func NewUI(data *Data, palette *Palette) *UI {
+	palette.Fit(data)
 	theme := material.NewTheme()
 	return &UI{
 		theme:   theme,
@@ -52,29 +209,105 @@ func NewUI(data *Data) *UI {
 			Theme:   theme,
 			Palette: palette,
 			Data:    data,
+			SortBy:  -1,
 		},
 
 		percentilePlot: &PercentilePlot{
-			Data: data,
+			Theme:   theme,
+			Palette: palette,
+			Data:    data,
 		},
 		trendPlot: &TrendPlot{
-			Data: data,
+			Theme:   theme,
+			Palette: palette,
+			Data:    data,
 		},
 		colorLegend: &ColorLegend{
+			Theme:   theme,
 			Data:    data,
 			Palette: palette,
 		},
 	}
 }
 
+// SetComparison switches the UI to show the change between two datasets.
+func (ui *UI) SetComparison(c *Comparison) {
+	ui.data = c.Delta
+	ui.palette.Fit(c.Delta)
+
+	ui.percentileGrid.Data = c.Delta
+	ui.percentileGrid.Comparison = c
+
+	ui.percentilePlot.Data = c.Head
+	ui.percentilePlot.Comparison = c
+	ui.trendPlot.Data = c.Head
+	ui.trendPlot.Comparison = c
+
+	ui.colorLegend.Data = c.Delta
+	ui.colorLegend.Format = formatPercent
+}
+
+// Chart returns the percentile grid as currently shown for exporting.
+func (ui *UI) Chart() *Chart {
+	return &Chart{
+		Data:       ui.data,
+		Palette:    ui.palette,
+		Comparison: ui.percentileGrid.Comparison,
+		Columns:    slices.Clone(ui.percentileGrid.Columns()),
+		Size:       ui.ExportSize,
+	}
+}
+
+// Export writes histviz.svg, histviz.png and histviz.csv to the working directory.
+func (ui *UI) Export() {
+	chart := ui.Chart()
+	for _, path := range []string{"histviz.svg", "histviz.png", "histviz.csv"} {
+		if err := chart.Export(path); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		fmt.Fprintln(os.Stderr, "exported", path)
+	}
+}
+
+// refresh replaces the data with the latest stream snapshot.
+func (ui *UI) refresh(now time.Time) {
+	*ui.data = *ui.Stream.Snapshot(now)
+	ui.palette.Fit(ui.data)
+	ui.lastRefresh = now
+}
+
 func (ui *UI) Run(w *app.Window) error {
 	var ops op.Ops
 
+	if ui.Stream != nil {
+		done := make(chan struct{})
+		defer close(done)
+
+		go func() {
+			ticker := time.NewTicker(ui.RefreshInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					w.Invalidate()
+				}
+			}
+		}()
+	}
+
 	for {
 		switch e := w.Event().(type) {
 		case app.DestroyEvent:
 			return e.Err
 		case app.FrameEvent:
+			// limit the update rate, since input also triggers frames
+			if ui.Stream != nil && e.Now.Sub(ui.lastRefresh) >= ui.RefreshInterval {
+				ui.refresh(e.Now)
+			}
+
 			gtx := app.NewContext(&ops, e)
 			ui.Layout(gtx)
 			e.Frame(gtx.Ops)
@@ -83,5 +316,72 @@ func (ui *UI) Run(w *app.Window) error {
 }
 
 func (ui *UI) Layout(gtx layout.Context) layout.Dimensions {
-	return ui.percentileGrid.Layout(gtx)
+	for {
+		ev, ok := gtx.Event(
+			key.Filter{Name: "S"},
+			key.Filter{Name: "C"},
+			key.Filter{Name: "E", Required: key.ModShortcut},
+		)
+		if !ok {
+			break
+		}
+		if gtx.Focused(&ui.filter) {
+			continue
+		}
+		if ev, ok := ev.(key.Event); ok && ev.State == key.Press {
+			switch ev.Name {
+			case "S":
+				ui.palette.Scale = ui.palette.Scale.Next()
+			case "C":
+				ui.palette.Colormap = NextColormap(ui.palette.Colormap)
+			case "E":
+				ui.Export()
+			}
+		}
+	}
+
+	for {
+		ev, ok := ui.filter.Update(gtx)
+		if !ok {
+			break
+		}
+		if _, ok := ev.(widget.ChangeEvent); ok {
+			ui.percentileGrid.Filter = ui.filter.Text()
+			ui.percentileGrid.Scroll = 0
+		}
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			ui.filter.SingleLine = true
+			return layout.UniformInset(8).Layout(gtx,
+				material.Editor(ui.theme, &ui.filter, "Filter metrics").Layout)
+		}),
+		layout.Flexed(0.6, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{}.Layout(gtx,
+				layout.Flexed(1, ui.percentileGrid.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					ui.colorLegend.Active = math.NaN()
+					if ui.percentileGrid.Hover {
+						at := ui.percentileGrid.HoverAt
+						ui.colorLegend.Active = ui.data.Metrics[at.X].Values[at.Y]
+					}
+
+					gtx.Constraints.Max.X = gtx.Dp(colorLegendWidth * 6)
+					return ui.colorLegend.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Flexed(0.4, func(gtx layout.Context) layout.Dimensions {
+			// the plots follow the hovered or selected cell
+			target := ui.percentileGrid.Target()
+			ui.percentilePlot.Col, ui.percentilePlot.Row = target.X, target.Y
+			ui.trendPlot.Col, ui.trendPlot.Row = target.X, target.Y
+
+			return layout.Flex{}.Layout(gtx,
+				layout.Flexed(0.5, ui.percentilePlot.Layout),
+				layout.Flexed(0.5, ui.trendPlot.Layout),
+			)
+		}),
+	)
 }
@@ -1,18 +1,272 @@
 package main
 
 import (
+	"fmt"
 	"image/color"
+	"math"
+	"slices"
+	"sort"
+	"strings"
 )
 
-type Palette struct{}
+// Palette maps values to colors.
+type Palette struct {
+	Scale    Scale
+	Colormap *Colormap
+	// Baseline is the value at the center of a diverging colormap,
+	// NaN uses the middle of the scale.
+	Baseline float64
 
-func (palette Palette) Color(value float64, span Range) color.NRGBA {
-	p := (value - span.Min) / (span.Max - span.Min)
+	// sorted contains all the values for ScaleQuantile.
+	sorted []float64
+}
+
+// NewPalette creates a palette with a log scale and viridis colormap.
+func NewPalette() *Palette {
+	return &Palette{
+		Scale:    ScaleLog,
+		Colormap: Viridis,
+		Baseline: math.NaN(),
+	}
+}
+
+// Fit updates the value distribution used by ScaleQuantile.
+func (palette *Palette) Fit(data *Data) {
+	palette.sorted = palette.sorted[:0]
+	for _, metric := range data.Metrics {
+		for _, v := range metric.Values {
+			if !math.IsNaN(v) {
+				palette.sorted = append(palette.sorted, v)
+			}
+		}
+	}
+	slices.Sort(palette.sorted)
+}
+
+func (palette *Palette) Color(value float64, span Range) color.NRGBA {
+	return palette.Colormap.At(palette.Position(value, span))
+}
+
+// Position returns the location of value in the colormap in range 0..1.
+func (palette *Palette) Position(value float64, span Range) float64 {
+	p := palette.Scale.Normalize(value, span, palette.sorted)
+	if !palette.Colormap.Diverging || math.IsNaN(palette.Baseline) {
+		return p
+	}
+
+	// stretch both sides of the baseline to cover half of the colormap
+	base := clamp(palette.Scale.Normalize(palette.Baseline, span, palette.sorted), 0, 1)
+	switch {
+	case p < base:
+		return 0.5 * p / base
+	case base < 1:
+		return 0.5 + 0.5*(p-base)/(1-base)
+	default:
+		return 1
+	}
+}
+
+// Ticks returns values suitable for labeling the scale.
+func (palette *Palette) Ticks(span Range) []float64 {
+	var ticks []float64
+	switch palette.Scale {
+	case ScaleLog:
+		ticks = logTicks(span)
+	case ScaleQuantile:
+		if len(palette.sorted) > 0 {
+			for _, p := range []float64{0, 0.25, 0.5, 0.75, 1} {
+				v := quantile(palette.sorted, p)
+				step := niceNumber(math.Abs(v)/10, true)
+				if step > 0 {
+					v = math.Round(v/step) * step
+				}
+				ticks = append(ticks, v)
+			}
+		}
+	default:
+		ticks, _ = niceTicks(span, 5)
+	}
+
+	return slices.DeleteFunc(slices.Compact(ticks), func(v float64) bool {
+		return v < span.Min || v > span.Max
+	})
+}
+
+// logTicks returns 1, 2, 5 multiples of powers of ten inside span.
+func logTicks(span Range) []float64 {
+	var ticks []float64
+	lo := span.Min
+	if lo <= 0 {
+		// values below 1 are compressed together by the shifted scale
+		if span.Max <= 1 {
+			ticks, _ := niceTicks(span, 5)
+			return ticks
+		}
+		ticks = append(ticks, 0)
+		lo = 1
+	}
+
+	for exp := math.Floor(math.Log10(lo)); exp <= math.Ceil(math.Log10(span.Max)); exp++ {
+		for _, m := range []float64{1, 2, 5} {
+			ticks = append(ticks, m*math.Pow(10, exp))
+		}
+	}
+	ticks = slices.DeleteFunc(ticks, func(v float64) bool {
+		return v < span.Min || v > span.Max
+	})
+	if len(ticks) > 6 {
+		// too crowded, keep only powers of ten
+		ticks = slices.DeleteFunc(ticks, func(v float64) bool {
+			return math.Abs(math.Log10(v)-math.Round(math.Log10(v))) > 1e-9
+		})
+	}
+	return ticks
+}
+
+// Scale defines how values are mapped to the colormap.
+type Scale int
+
+const (
+	ScaleLinear Scale = iota
+	ScaleLog
+	ScaleSqrt
+	ScaleQuantile
+)
+
+var scaleNames = []string{"linear", "log", "sqrt", "quantile"}
+
+func (scale Scale) String() string {
+	if int(scale) < len(scaleNames) {
+		return scaleNames[scale]
+	}
+	return fmt.Sprintf("Scale(%d)", int(scale))
+}
+
+// ParseScale finds the scale by name.
+func ParseScale(name string) (Scale, error) {
+	for i, s := range scaleNames {
+		if s == name {
+			return Scale(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown scale %q, expected one of %s", name, strings.Join(scaleNames, ", "))
+}
+
+// Next returns the following scale, used for cycling through them.
+func (scale Scale) Next() Scale { return (scale + 1) % Scale(len(scaleNames)) }
+
+// Normalize maps value in span to range 0..1. ScaleQuantile uses the
+// rank of the value in sorted instead of span.
+func (scale Scale) Normalize(value float64, span Range, sorted []float64) float64 {
+	if !(span.Max > span.Min) {
+		return 0.5
+	}
+
+	switch scale {
+	case ScaleLog:
+		if span.Min > 0 {
+			return clamp(math.Log(value/span.Min)/math.Log(span.Max/span.Min), 0, 1)
+		}
+		// shift the values so that the logarithm is defined
+		return clamp(math.Log1p(max(value-span.Min, 0))/math.Log1p(span.Max-span.Min), 0, 1)
+	case ScaleSqrt:
+		return math.Sqrt(clamp(invlerp(value, span.Min, span.Max), 0, 1))
+	case ScaleQuantile:
+		if len(sorted) < 2 {
+			return clamp(invlerp(value, span.Min, span.Max), 0, 1)
+		}
+		i := sort.SearchFloat64s(sorted, value)
+		switch {
+		case i == 0:
+			return 0
+		case i >= len(sorted):
+			return 1
+		}
+		// interpolate between the neighbouring ranks
+		lo, hi := sorted[i-1], sorted[i]
+		rank := float64(i - 1)
+		if hi > lo {
+			rank += invlerp(value, lo, hi)
+		}
+		return rank / float64(len(sorted)-1)
+	default:
+		return clamp(invlerp(value, span.Min, span.Max), 0, 1)
+	}
+}
+
+// Colormap interpolates between evenly spaced color stops.
+type Colormap struct {
+	Name  string
+	Stops []color.NRGBA
+	// Diverging colormaps are centered around Palette.Baseline.
+	Diverging bool
+}
+
+// At returns the color at p in range 0..1.
+func (colormap *Colormap) At(p float64) color.NRGBA {
+	if math.IsNaN(p) {
+		return color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xFF}
+	}
+	n := len(colormap.Stops) - 1
+	at := clamp(p, 0, 1) * float64(n)
+	i := min(int(at), n-1)
+	return RGBALerp(colormap.Stops[i], colormap.Stops[i+1], float32(at-float64(i)))
+}
+
+var (
+	// Viridis is a perceptually uniform colormap from dark blue to yellow.
+	Viridis = &Colormap{
+		Name: "viridis",
+		Stops: []color.NRGBA{
+			NRGBAHex(0x440154FF), NRGBAHex(0x482878FF), NRGBAHex(0x3E4989FF), NRGBAHex(0x31688EFF),
+			NRGBAHex(0x26828EFF), NRGBAHex(0x1F9E89FF), NRGBAHex(0x35B779FF), NRGBAHex(0x6ECE58FF),
+			NRGBAHex(0xB5DE2BFF), NRGBAHex(0xFDE725FF),
+		},
+	}
+	// Magma is a perceptually uniform colormap from black to light yellow.
+	Magma = &Colormap{
+		Name: "magma",
+		Stops: []color.NRGBA{
+			NRGBAHex(0x000004FF), NRGBAHex(0x180F3DFF), NRGBAHex(0x440F76FF), NRGBAHex(0x721F81FF),
+			NRGBAHex(0x9E2F7FFF), NRGBAHex(0xCD4071FF), NRGBAHex(0xF1605DFF), NRGBAHex(0xFD9668FF),
+			NRGBAHex(0xFECA8DFF), NRGBAHex(0xFCFDBFFF),
+		},
+	}
+	// Diverging goes from blue through white to red.
+	Diverging = &Colormap{
+		Name: "diverging",
+		Stops: []color.NRGBA{
+			NRGBAHex(0x2166ACFF), NRGBAHex(0x67A9CFFF), NRGBAHex(0xD1E5F0FF), NRGBAHex(0xF7F7F7FF),
+			NRGBAHex(0xFDDBC7FF), NRGBAHex(0xEF8A62FF), NRGBAHex(0xB2182BFF),
+		},
+		Diverging: true,
+	}
+	// Hue is the original hue based colormap.
+	Hue = &Colormap{
+		Name: "hue",
+		Stops: []color.NRGBA{
+			HSL(0, 0.6, 0.6), HSL(0.125, 0.6, 0.6), HSL(0.25, 0.6, 0.6),
+			HSL(0.375, 0.6, 0.6), HSL(0.5, 0.6, 0.6),
+		},
+	}
+
+	Colormaps = []*Colormap{Viridis, Magma, Diverging, Hue}
+)
+
+// ParseColormap finds the colormap by name.
+func ParseColormap(name string) (*Colormap, error) {
+	var names []string
+	for _, colormap := range Colormaps {
+		if colormap.Name == name {
+			return colormap, nil
+		}
+		names = append(names, colormap.Name)
+	}
+	return nil, fmt.Errorf("unknown colormap %q, expected one of %s", name, strings.Join(names, ", "))
+}
 
-	sat, lit := float32(0.6), float32(0.6)
-	// if highlight {
-	// 	sat = 0.8
-	// 	lit = 0.7
-	// }
-	return HSL(float32(p*0.5), sat, lit)
+// NextColormap returns the colormap following current, used for cycling through them.
+func NextColormap(current *Colormap) *Colormap {
+	i := slices.Index(Colormaps, current)
+	return Colormaps[(i+1)%len(Colormaps)]
 }
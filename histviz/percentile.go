@@ -1,7 +1,9 @@
 package main
 
 import (
+	"math"
 	"math/rand"
+	"slices"
 	"sort"
 	"strconv"
 )
@@ -20,12 +22,12 @@ type Percentile struct {
 type Metric struct {
 	Label  string
 	Values []float64
+	// Samples are the measurements the Values were calculated from, when available.
+	Samples []float64
 }
 
-func RandomData() *Data {
-	data := &Data{}
-
-	data.Percentiles = []Percentile{
+func DefaultPercentiles() []Percentile {
+	return []Percentile{
 		{Label: "p0.1", Value: 0.001},
 		{Label: "p1", Value: 0.01},
 		{Label: "p5", Value: 0.05},
@@ -38,6 +40,12 @@ func RandomData() *Data {
 		{Label: "p99", Value: 0.99},
 		{Label: "p99.9", Value: 0.999},
 	}
+}
+
+func RandomData() *Data {
+	data := &Data{}
+
+	data.Percentiles = DefaultPercentiles()
 	data.Metrics = make([]Metric, 32)
 
 	for i := range data.Metrics {
@@ -61,3 +69,63 @@ func RandomData() *Data {
 
 	return data
 }
+
+// NewData creates data from metrics. The percentiles are calculated
+// from the samples for metrics that don't have Values.
+func NewData(percentiles []Percentile, metrics []Metric) *Data {
+	data := &Data{
+		Metrics:     metrics,
+		Percentiles: percentiles,
+	}
+
+	for i := range data.Metrics {
+		metric := &data.Metrics[i]
+		if metric.Values != nil {
+			continue
+		}
+		slices.Sort(metric.Samples)
+
+		metric.Values = make([]float64, len(percentiles))
+		for k, p := range percentiles {
+			metric.Values[k] = quantile(metric.Samples, p.Value)
+		}
+	}
+
+	data.UpdateRange()
+	return data
+}
+
+// UpdateRange updates Range to cover all the metric values.
+func (data *Data) UpdateRange() {
+	data.Range = Range{Min: math.Inf(1), Max: math.Inf(-1)}
+	for _, metric := range data.Metrics {
+		for _, v := range metric.Values {
+			if math.IsNaN(v) {
+				continue
+			}
+			data.Range.Min = min(data.Range.Min, v)
+			data.Range.Max = max(data.Range.Max, v)
+		}
+	}
+	if data.Range.Min > data.Range.Max {
+		data.Range = Range{}
+	}
+}
+
+// quantile returns the p-th quantile of the sorted samples,
+// interpolating linearly between the closest ranks.
+func quantile(sorted []float64, p float64) float64 {
+	switch len(sorted) {
+	case 0:
+		return math.NaN()
+	case 1:
+		return sorted[0]
+	}
+
+	h := p * float64(len(sorted)-1)
+	lo := int(math.Floor(h))
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return lerp(h-float64(lo), sorted[lo], sorted[lo+1])
+}
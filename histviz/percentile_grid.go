@@ -1,8 +1,13 @@
 package main
 
 import (
+	"cmp"
 	"image"
 	"image/color"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
 
 	"gioui.org/font"
 	"gioui.org/io/event"
@@ -21,12 +26,33 @@ type PercentileGridPlot struct {
 	Theme   *material.Theme
 	Palette *Palette
 	Data    *Data
-
+	// Comparison, when set, is used to describe the hovered cell.
+	// Data should be Comparison.Delta.
+	Comparison *Comparison
+
+	// Filter hides metrics whose label doesn't contain it, ignoring case.
+	Filter string
+	// SortBy is the percentile used for ordering the metrics, -1 keeps
+	// the original order.
+	SortBy         int
+	SortDescending bool
+	// Scroll is the horizontal scroll offset in pixels.
+	Scroll int
+
+	// Hover, HoverAt and Selected use data coordinates, where X is the
+	// index in Data.Metrics and Y the index in Data.Percentiles.
 	Hover    bool
 	HoverAt  image.Point
 	Selected image.Point
+
+	// columns contains the visible metric indices in the display order.
+	columns []int
 }
 
+// minCellWidth is the narrowest readable cell, the grid scrolls
+// when the metrics don't fit.
+var minCellWidth = unit.Dp(32)
+
 func (w *PercentileGridPlot) Color(value float64) color.NRGBA {
 	return w.Palette.Color(value, w.Data.Range)
 }
@@ -39,6 +65,73 @@ func (w *PercentileGridPlot) Target() image.Point {
 	}
 }
 
+// Columns returns the visible metric indices in display order.
+func (w *PercentileGridPlot) Columns() []int {
+	filter := strings.ToLower(w.Filter)
+
+	w.columns = w.columns[:0]
+	for i := range w.Data.Metrics {
+		if strings.Contains(strings.ToLower(w.Data.Metrics[i].Label), filter) {
+			w.columns = append(w.columns, i)
+		}
+	}
+
+	if w.SortBy >= 0 && w.SortBy < len(w.Data.Percentiles) {
+		value := func(i int) float64 {
+			if values := w.Data.Metrics[i].Values; w.SortBy < len(values) {
+				return values[w.SortBy]
+			}
+			return math.NaN()
+		}
+		slices.SortStableFunc(w.columns, func(a, b int) int {
+			va, vb := value(a), value(b)
+			// keep missing values last regardless of the direction
+			switch {
+			case math.IsNaN(va) && math.IsNaN(vb):
+				return 0
+			case math.IsNaN(va):
+				return 1
+			case math.IsNaN(vb):
+				return -1
+			case w.SortDescending:
+				return cmp.Compare(vb, va)
+			default:
+				return cmp.Compare(va, vb)
+			}
+		})
+	}
+
+	return w.columns
+}
+
+// Sort orders the metrics by the percentile, sorting by the same
+// percentile again reverses the order.
+func (w *PercentileGridPlot) Sort(percentile int) {
+	if w.SortBy == percentile {
+		w.SortDescending = !w.SortDescending
+		return
+	}
+	w.SortBy = percentile
+	w.SortDescending = false
+}
+
+// Describe returns the tooltip text for the cell.
+func (w *PercentileGridPlot) Describe(at image.Point) []string {
+	if w.Comparison != nil {
+		return w.Comparison.Describe(at)
+	}
+
+	metric := &w.Data.Metrics[at.X]
+	value := math.NaN()
+	if at.Y < len(metric.Values) {
+		value = metric.Values[at.Y]
+	}
+	return []string{
+		metric.Label,
+		w.Data.Percentiles[at.Y].Label + " = " + strconv.FormatFloat(value, 'g', -1, 64),
+	}
+}
+
 func (w *PercentileGridPlot) Layout(gtx layout.Context) layout.Dimensions {
 	totalSize := gtx.Constraints.Constrain(gtx.Constraints.Max)
 
@@ -54,19 +147,27 @@ func (w *PercentileGridPlot) Layout(gtx layout.Context) layout.Dimensions {
 		Y: totalSize.Y - axisSize.Y,
 	}
 
+	columns := w.Columns()
 	cellcount := image.Point{
-		X: len(w.Data.Metrics),
+		X: len(columns),
 		Y: len(w.Data.Percentiles),
 	}
+	if cellcount.X == 0 || cellcount.Y == 0 || gridSize.X <= 0 || gridSize.Y <= 0 {
+		w.Hover = false
+		return layout.Dimensions{Size: totalSize}
+	}
 
 	// calculate integer cell sizes
 	cellSize := image.Point{
-		X: gridSize.X / cellcount.X,
+		X: max(gridSize.X/cellcount.X, gtx.Dp(minCellWidth)),
 		Y: gridSize.Y / cellcount.Y,
 	}
 
-	// add any left over pixels for the header
-	axisSize.X = totalSize.X - cellSize.X*cellcount.X
+	scrolling := cellSize.X*cellcount.X > gridSize.X
+	if !scrolling {
+		// add any left over pixels for the header
+		axisSize.X = totalSize.X - cellSize.X*cellcount.X
+	}
 	axisSize.Y = totalSize.Y - cellSize.Y*cellcount.Y
 
 	// final size of the grid
@@ -75,6 +176,15 @@ func (w *PercentileGridPlot) Layout(gtx layout.Context) layout.Dimensions {
 		Y: totalSize.Y - int(axisSize.Y),
 	}
 
+	maxScroll := max(cellSize.X*cellcount.X-gridSize.X, 0)
+	w.Scroll = min(max(w.Scroll, 0), maxScroll)
+
+	// column finds the display column of the metric
+	column := func(metric int) (int, bool) {
+		i := slices.Index(columns, metric)
+		return i, i >= 0
+	}
+
 	func() {
 		defer op.Offset(axisSize).Push(gtx.Ops).Pop()
 
@@ -87,8 +197,10 @@ func (w *PercentileGridPlot) Layout(gtx layout.Context) layout.Dimensions {
 
 		for {
 			ev, ok := gtx.Event(pointer.Filter{
-				Target: w,
-				Kinds:  pointer.Move | pointer.Enter | pointer.Leave | pointer.Cancel | pointer.Press,
+				Target:  w,
+				Kinds:   pointer.Move | pointer.Enter | pointer.Leave | pointer.Cancel | pointer.Press | pointer.Scroll,
+				ScrollX: pointer.ScrollRange{Min: -w.Scroll, Max: maxScroll - w.Scroll},
+				ScrollY: pointer.ScrollRange{Min: -w.Scroll, Max: maxScroll - w.Scroll},
 			})
 			if !ok {
 				break
@@ -96,13 +208,30 @@ func (w *PercentileGridPlot) Layout(gtx layout.Context) layout.Dimensions {
 
 			switch ev := ev.(type) {
 			case pointer.Event:
+				if ev.Kind == pointer.Scroll {
+					// vertical wheel scrolls horizontally as well
+					w.Scroll = min(max(w.Scroll+int(ev.Scroll.X+ev.Scroll.Y), 0), maxScroll)
+				}
+
+				pos := ev.Position
+				if ev.Kind == pointer.Press && ev.Buttons == pointer.ButtonPrimary && pos.X < 0 {
+					if pos.Y < 0 {
+						// corner resets the order
+						w.SortBy = -1
+					} else {
+						w.Sort(min(int(pos.Y/float32(cellSize.Y)), cellcount.Y-1))
+					}
+					continue
+				}
+
 				target := image.Point{
-					X: int(ev.Position.X / float32(cellSize.X)),
-					Y: int(ev.Position.Y / float32(cellSize.Y)),
+					X: int((pos.X + float32(w.Scroll)) / float32(cellSize.X)),
+					Y: int(pos.Y / float32(cellSize.Y)),
 				}
 
 				target.X = min(max(0, target.X), cellcount.X-1)
 				target.Y = min(max(0, target.Y), cellcount.Y-1)
+				target.X = columns[target.X]
 
 				w.HoverAt = target
 
@@ -111,7 +240,7 @@ func (w *PercentileGridPlot) Layout(gtx layout.Context) layout.Dimensions {
 				}
 
 				switch ev.Kind {
-				case pointer.Enter, pointer.Press, pointer.Move, pointer.Drag:
+				case pointer.Enter, pointer.Press, pointer.Move, pointer.Drag, pointer.Scroll:
 					w.Hover = true
 				case pointer.Leave, pointer.Cancel:
 					w.Hover = false
@@ -119,45 +248,8 @@ func (w *PercentileGridPlot) Layout(gtx layout.Context) layout.Dimensions {
 			}
 		}
 
-		// draw cells
-		for x := range w.Data.Metrics {
-			metric := &w.Data.Metrics[x]
-			for y, value := range metric.Values {
-				zero := mulpoint(cellSize, image.Point{X: x, Y: y})
-				cell := clip.Rect{
-					Min: zero,
-					Max: zero.Add(cellSize),
-				}
-
-				stack := cell.Push(gtx.Ops)
-				paint.ColorOp{Color: w.Color(value)}.Add(gtx.Ops)
-				paint.PaintOp{}.Add(gtx.Ops)
-
-				stack.Pop()
-			}
-		}
-
 		target := w.Target()
 
-		func() { // draw highlighted column
-			zero := image.Point{
-				X: cellSize.X * target.X,
-				Y: -axisSize.Y,
-			}
-
-			StrokeRect{
-				Rect: image.Rectangle{
-					Min: zero,
-					Max: image.Point{
-						X: zero.X + cellSize.X,
-						Y: gridSize.Y,
-					},
-				},
-				Inset: gtx.Dp(-4),
-				Color: color.NRGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x40},
-			}.Add(gtx.Ops)
-		}()
-
 		func() { // draw highlighted row
 			zero := image.Point{
 				X: -axisSize.X,
@@ -177,53 +269,106 @@ func (w *PercentileGridPlot) Layout(gtx layout.Context) layout.Dimensions {
 			}.Add(gtx.Ops)
 		}()
 
-		func() { // draw highlighted element
-			zero := mulpoint(cellSize, target)
-
-			StrokeRect{
-				Rect: image.Rectangle{
-					Min: zero,
-					Max: zero.Add(cellSize),
-				},
-				Inset: -gtx.Dp(4),
-				Color: color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0x80},
-			}.Add(gtx.Ops)
-		}()
-
-		func() { // draw column labels
-			lineHeight := axisLineHeight
-			textHeight := lineHeight * 2 / 3
-
-			lgtx := gtx
-			lgtx.Constraints.Max = image.Point{
-				X: cellSize.X,
-				Y: axisSize.Y,
+		func() { // draw scrolled content
+			defer clip.Rect{Min: image.Pt(0, -axisSize.Y), Max: gridSize}.Push(gtx.Ops).Pop()
+			defer op.Offset(image.Pt(-w.Scroll, 0)).Push(gtx.Ops).Pop()
+
+			// draw cells
+			for x, index := range columns {
+				metric := &w.Data.Metrics[index]
+				for y, value := range metric.Values {
+					zero := mulpoint(cellSize, image.Point{X: x, Y: y})
+					cell := clip.Rect{
+						Min: zero,
+						Max: zero.Add(cellSize),
+					}
+
+					stack := cell.Push(gtx.Ops)
+					paint.ColorOp{Color: w.Color(value)}.Add(gtx.Ops)
+					paint.PaintOp{}.Add(gtx.Ops)
+
+					stack.Pop()
+				}
 			}
-			lgtx.Constraints.Min = lgtx.Constraints.Max
 
-			for x := range w.Data.Metrics {
-				metric := &w.Data.Metrics[x]
-
-				stack := op.Offset(image.Point{
-					X: x * cellSize.X,
-					Y: -axisSize.Y,
-				}).Push(gtx.Ops)
-
-				colMacro := op.Record(gtx.Ops)
-				paint.ColorOp{
-					Color: color.NRGBA{A: 0xFF},
-				}.Add(gtx.Ops)
-				colorOp := colMacro.Stop()
+			col, visible := column(target.X)
+			if visible {
+				func() { // draw highlighted column
+					zero := image.Point{
+						X: cellSize.X * col,
+						Y: -axisSize.Y,
+					}
+
+					StrokeRect{
+						Rect: image.Rectangle{
+							Min: zero,
+							Max: image.Point{
+								X: zero.X + cellSize.X,
+								Y: gridSize.Y,
+							},
+						},
+						Inset: gtx.Dp(-4),
+						Color: color.NRGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x40},
+					}.Add(gtx.Ops)
+				}()
+
+				func() { // draw highlighted element
+					zero := mulpoint(cellSize, image.Point{X: col, Y: target.Y})
+
+					StrokeRect{
+						Rect: image.Rectangle{
+							Min: zero,
+							Max: zero.Add(cellSize),
+						},
+						Inset: -gtx.Dp(4),
+						Color: color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0x80},
+					}.Add(gtx.Ops)
+				}()
+			}
 
-				widget.Label{
-					Alignment:  text.Middle,
-					MaxLines:   1,
-					Truncator:  "...",
-					LineHeight: lineHeight,
-				}.Layout(lgtx, w.Theme.Shaper, font.Font{}, textHeight, metric.Label, colorOp)
+			func() { // draw column labels
+				lineHeight := axisLineHeight
+				textHeight := lineHeight * 2 / 3
 
-				stack.Pop()
-			}
+				lgtx := gtx
+				lgtx.Constraints.Max = image.Point{
+					X: cellSize.X,
+					Y: axisSize.Y,
+				}
+				lgtx.Constraints.Min = lgtx.Constraints.Max
+
+				for x, index := range columns {
+					metric := &w.Data.Metrics[index]
+
+					label := metric.Label
+					if w.Comparison != nil {
+						// mark changes that are not significant similarly to benchstat
+						if significant, ok := w.Comparison.Significant(index); ok && !significant {
+							label = "~ " + label
+						}
+					}
+
+					stack := op.Offset(image.Point{
+						X: x * cellSize.X,
+						Y: -axisSize.Y,
+					}).Push(gtx.Ops)
+
+					colMacro := op.Record(gtx.Ops)
+					paint.ColorOp{
+						Color: color.NRGBA{A: 0xFF},
+					}.Add(gtx.Ops)
+					colorOp := colMacro.Stop()
+
+					widget.Label{
+						Alignment:  text.Middle,
+						MaxLines:   1,
+						Truncator:  "...",
+						LineHeight: lineHeight,
+					}.Layout(lgtx, w.Theme.Shaper, font.Font{}, textHeight, label, colorOp)
+
+					stack.Pop()
+				}
+			}()
 		}()
 
 		func() { // draw row labels
@@ -239,6 +384,13 @@ func (w *PercentileGridPlot) Layout(gtx layout.Context) layout.Dimensions {
 
 			for y, value := range w.Data.Percentiles {
 				label := value.Label
+				if y == w.SortBy {
+					if w.SortDescending {
+						label += " ▼"
+					} else {
+						label += " ▲"
+					}
+				}
 
 				stack := op.Offset(image.Point{
 					X: -axisSize.X,
@@ -261,6 +413,14 @@ func (w *PercentileGridPlot) Layout(gtx layout.Context) layout.Dimensions {
 				stack.Pop()
 			}
 		}()
+
+		if col, visible := column(w.HoverAt.X); w.Hover && visible { // draw tooltip
+			anchor := mulpoint(cellSize, image.Point{X: col, Y: w.HoverAt.Y}).Add(cellSize)
+			DrawTooltip(gtx, w.Theme,
+				anchor.Sub(image.Pt(w.Scroll, 0)),
+				image.Rectangle{Min: axisSize.Mul(-1), Max: gridSize},
+				w.Describe(w.HoverAt))
+		}
 	}()
 
 	return layout.Dimensions{Size: totalSize}
@@ -1,17 +1,106 @@
 package main
 
 import (
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/f32"
 	"gioui.org/layout"
+	"gioui.org/widget/material"
 )
 
+// PercentilePlot draws the percentile curve of a single metric using
+// a logarithmic percentile axis.
 type PercentilePlot struct {
-	Data *Data
-	Col  int
+	Theme   *material.Theme
+	Palette *Palette
+	Data    *Data
+	// Comparison, when set, draws the base values as a reference and
+	// colors the values by the change. Data should be Comparison.Head.
+	Comparison *Comparison
+
+	// Col is the metric to draw.
+	Col int
+	// Row is the highlighted percentile.
+	Row int
 }
 
 func (plot *PercentilePlot) Layout(gtx layout.Context) layout.Dimensions {
 	gtx.Constraints.Min = gtx.Constraints.Max
 	size := gtx.Constraints.Max
 
+	data := plot.Data
+	if plot.Col < 0 || plot.Col >= len(data.Metrics) || len(data.Percentiles) == 0 {
+		return layout.Dimensions{Size: size}
+	}
+	metric := &data.Metrics[plot.Col]
+
+	maxNines := 1
+	for _, p := range data.Percentiles {
+		maxNines = max(maxNines, int(math.Ceil(nines(p.Value)-1e-6)))
+	}
+
+	span := valueRange(data.Range)
+	if plot.Comparison != nil {
+		span = valueRange(plot.Comparison.Range())
+	}
+	yticks, yrange := niceTicks(span, 4)
+	area := NewPlotArea(gtx, size, Range{Min: 0, Max: float64(maxNines)}, yrange)
+	if area.Empty() {
+		return layout.Dimensions{Size: size}
+	}
+
+	var xticks []float64
+	var xlabels []string
+	for n := 0; n <= maxNines; n++ {
+		xticks = append(xticks, float64(n))
+		xlabels = append(xlabels, ninesLabel(n))
+	}
+
+	area.HorizontalGrid(gtx, plot.Theme, yticks)
+	area.VerticalGrid(gtx, plot.Theme, xticks, xlabels)
+	area.Title(gtx, plot.Theme, metric.Label)
+
+	curve := func(values []float64) []f32.Point {
+		points := make([]f32.Point, len(values))
+		for i, v := range values {
+			points[i] = area.Pt(nines(data.Percentiles[i].Value), v)
+		}
+		return points
+	}
+
+	if plot.Comparison != nil {
+		area.Polyline(gtx, curve(plot.Comparison.Base.Metrics[plot.Col].Values), plotFocusColor)
+	}
+	points := curve(metric.Values)
+	area.Polyline(gtx, points, plotLineColor)
+
+	for i, v := range metric.Values {
+		if math.IsNaN(v) {
+			continue
+		}
+		if i == plot.Row {
+			area.Dot(gtx, points[i], plotDotRadius*2, plotFocusColor)
+		}
+		area.Dot(gtx, points[i], plotDotRadius, plot.color(i, v))
+	}
+
+	if plot.Row >= 0 && plot.Row < len(metric.Values) {
+		label := data.Percentiles[plot.Row].Label + " = " + formatValue(metric.Values[plot.Row])
+		if c := plot.Comparison; c != nil {
+			label += " (" + formatPercent(c.Delta.Metrics[plot.Col].Values[plot.Row]) + ")"
+		}
+		DrawText(gtx, plot.Theme, image.Pt(area.Rect.Max.X, area.Rect.Min.Y-gtx.Dp(4)), layout.SE, label)
+	}
+
 	return layout.Dimensions{Size: size}
 }
+
+// color returns the color for the value in the specified row.
+func (plot *PercentilePlot) color(row int, value float64) color.NRGBA {
+	if c := plot.Comparison; c != nil {
+		return plot.Palette.Color(c.Delta.Metrics[plot.Col].Values[row], c.Delta.Range)
+	}
+	return plot.Palette.Color(value, plot.Data.Range)
+}
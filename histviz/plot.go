@@ -0,0 +1,225 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"gioui.org/f32"
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var (
+	plotTextSize  = unit.Sp(12)
+	plotLineWidth = unit.Dp(2)
+	plotDotRadius = unit.Dp(3)
+
+	plotGridColor  = color.NRGBA{A: 0x20}
+	plotLineColor  = color.NRGBA{R: 0x30, G: 0x30, B: 0x30, A: 0xFF}
+	plotFocusColor = color.NRGBA{A: 0x40}
+)
+
+// PlotArea maps data values to the screen inside Rect.
+type PlotArea struct {
+	Rect image.Rectangle
+	X, Y Axis
+}
+
+// NewPlotArea creates a plot area leaving space for axis labels.
+func NewPlotArea(gtx layout.Context, size image.Point, x, y Range) PlotArea {
+	rect := image.Rectangle{
+		Min: image.Pt(gtx.Dp(56), gtx.Sp(plotTextSize*2)),
+		Max: image.Pt(size.X-gtx.Dp(16), size.Y-gtx.Sp(plotTextSize*2)),
+	}
+	return PlotArea{
+		Rect: rect,
+		X:    Axis{Data: x, Screen: Range{Min: float64(rect.Min.X), Max: float64(rect.Max.X)}},
+		Y:    Axis{Data: y, Screen: Range{Min: float64(rect.Max.Y), Max: float64(rect.Min.Y)}},
+	}
+}
+
+// Empty returns whether there's no space for drawing.
+func (area PlotArea) Empty() bool { return area.Rect.Dx() <= 0 || area.Rect.Dy() <= 0 }
+
+// Pt converts data coordinates to screen.
+func (area PlotArea) Pt(x, y float64) f32.Point {
+	return f32.Point{
+		X: float32(area.X.ToScreen(x)),
+		Y: float32(area.Y.ToScreen(y)),
+	}
+}
+
+// HorizontalGrid draws lines and labels for the y values.
+func (area PlotArea) HorizontalGrid(gtx layout.Context, th *material.Theme, values []float64) {
+	for _, v := range values {
+		y := int(math.Round(area.Y.ToScreen(v)))
+		paint.FillShape(gtx.Ops, plotGridColor, clip.Rect{
+			Min: image.Pt(area.Rect.Min.X, y),
+			Max: image.Pt(area.Rect.Max.X, y+1),
+		}.Op())
+		DrawText(gtx, th, image.Pt(area.Rect.Min.X-gtx.Dp(4), y), layout.E, formatValue(v))
+	}
+}
+
+// VerticalGrid draws lines and labels for the x values.
+func (area PlotArea) VerticalGrid(gtx layout.Context, th *material.Theme, values []float64, labels []string) {
+	for i, v := range values {
+		x := int(math.Round(area.X.ToScreen(v)))
+		paint.FillShape(gtx.Ops, plotGridColor, clip.Rect{
+			Min: image.Pt(x, area.Rect.Min.Y),
+			Max: image.Pt(x+1, area.Rect.Max.Y),
+		}.Op())
+		DrawText(gtx, th, image.Pt(x, area.Rect.Max.Y+gtx.Dp(2)), layout.N, labels[i])
+	}
+}
+
+// Title draws a label above the plot.
+func (area PlotArea) Title(gtx layout.Context, th *material.Theme, title string) {
+	DrawText(gtx, th, image.Pt(area.Rect.Min.X, area.Rect.Min.Y-gtx.Dp(4)), layout.SW, title)
+}
+
+// Polyline strokes line between points, NaN values break the line.
+func (area PlotArea) Polyline(gtx layout.Context, points []f32.Point, col color.NRGBA) {
+	var path clip.Path
+	path.Begin(gtx.Ops)
+	drawing := false
+	for _, p := range points {
+		if math.IsNaN(float64(p.X)) || math.IsNaN(float64(p.Y)) {
+			drawing = false
+			continue
+		}
+		if drawing {
+			path.LineTo(p)
+		} else {
+			path.MoveTo(p)
+			drawing = true
+		}
+	}
+
+	paint.FillShape(gtx.Ops, col, clip.Stroke{
+		Path:  path.End(),
+		Width: float32(gtx.Dp(plotLineWidth)),
+	}.Op())
+}
+
+// Dot draws a circle at p.
+func (area PlotArea) Dot(gtx layout.Context, p f32.Point, radius unit.Dp, col color.NRGBA) {
+	r := float32(gtx.Dp(radius))
+	paint.FillShape(gtx.Ops, col, clip.Ellipse{
+		Min: image.Pt(int(p.X-r), int(p.Y-r)),
+		Max: image.Pt(int(p.X+r), int(p.Y+r)),
+	}.Op(gtx.Ops))
+}
+
+// DrawText draws txt so that the alignment point of the text is at pos.
+func DrawText(gtx layout.Context, th *material.Theme, pos image.Point, align layout.Direction, txt string) {
+	colMacro := op.Record(gtx.Ops)
+	paint.ColorOp{Color: color.NRGBA{A: 0xFF}}.Add(gtx.Ops)
+	colorOp := colMacro.Stop()
+
+	lgtx := gtx
+	lgtx.Constraints = layout.Constraints{Max: image.Pt(gtx.Dp(400), gtx.Sp(plotTextSize*2))}
+
+	macro := op.Record(gtx.Ops)
+	dims := widget.Label{MaxLines: 1}.Layout(lgtx, th.Shaper, font.Font{}, plotTextSize, txt, colorOp)
+	call := macro.Stop()
+
+	defer op.Offset(pos.Add(align.Position(dims.Size, image.Point{}))).Push(gtx.Ops).Pop()
+	call.Add(gtx.Ops)
+}
+
+// DrawTooltip draws lines in a box next to anchor, keeping it inside bounds.
+func DrawTooltip(gtx layout.Context, th *material.Theme, anchor image.Point, bounds image.Rectangle, lines []string) {
+	pad := gtx.Dp(4)
+	lineHeight := gtx.Sp(plotTextSize * 3 / 2)
+
+	lgtx := gtx
+	lgtx.Constraints = layout.Constraints{Max: image.Pt(gtx.Dp(400), lineHeight)}
+
+	macro := op.Record(gtx.Ops)
+	width := 0
+	for i, line := range lines {
+		stack := op.Offset(image.Pt(pad, pad+i*lineHeight)).Push(gtx.Ops)
+		colMacro := op.Record(gtx.Ops)
+		paint.ColorOp{Color: color.NRGBA{A: 0xFF}}.Add(gtx.Ops)
+		colorOp := colMacro.Stop()
+		dims := widget.Label{MaxLines: 1}.Layout(lgtx, th.Shaper, font.Font{}, plotTextSize, line, colorOp)
+		width = max(width, dims.Size.X)
+		stack.Pop()
+	}
+	call := macro.Stop()
+
+	size := image.Pt(width+2*pad, len(lines)*lineHeight+2*pad)
+	offset := anchor.Add(image.Pt(pad*2, pad*2))
+	if offset.X+size.X > bounds.Max.X {
+		offset.X = anchor.X - size.X - pad*2
+	}
+	if offset.Y+size.Y > bounds.Max.Y {
+		offset.Y = anchor.Y - size.Y - pad*2
+	}
+	offset.X = max(offset.X, bounds.Min.X)
+	offset.Y = max(offset.Y, bounds.Min.Y)
+
+	defer op.Offset(offset).Push(gtx.Ops).Pop()
+	box := image.Rectangle{Max: size}
+	paint.FillShape(gtx.Ops, color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xF0}, clip.UniformRRect(box, pad).Op(gtx.Ops))
+	paint.FillShape(gtx.Ops, plotFocusColor, clip.Stroke{
+		Path:  clip.UniformRRect(box, pad).Path(gtx.Ops),
+		Width: 1,
+	}.Op())
+	call.Add(gtx.Ops)
+}
+
+// niceTicks returns rounded values covering span with approximately count steps.
+func niceTicks(span Range, count int) (ticks []float64, covered Range) {
+	if !(span.Max > span.Min) {
+		span.Max = span.Min + 1
+	}
+
+	step := niceNumber((span.Max-span.Min)/float64(count), true)
+	covered = Range{
+		Min: math.Floor(span.Min/step) * step,
+		Max: math.Ceil(span.Max/step) * step,
+	}
+	for v := covered.Min; v <= covered.Max+step/2; v += step {
+		ticks = append(ticks, v)
+	}
+	return ticks, covered
+}
+
+// nines converts percentile p to a log scale where
+// p90 = 1, p99 = 2, p99.9 = 3.
+func nines(p float64) float64 {
+	return -math.Log10(1 - min(p, 1-1e-9))
+}
+
+// ninesLabel returns the percentile label for nines(p) == n.
+func ninesLabel(n int) string {
+	switch n {
+	case 0:
+		return "p0"
+	case 1:
+		return "p90"
+	case 2:
+		return "p99"
+	}
+	return "p99." + strings.Repeat("9", n-2)
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
+
+// valueRange returns the span extended to include zero.
+func valueRange(span Range) Range {
+	return Range{Min: min(span.Min, 0), Max: max(span.Max, 0)}
+}
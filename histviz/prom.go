@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ReadPrometheus parses Prometheus text exposition format and creates a
+// metric for each histogram label set, e.g.
+//
+//	http_request_duration_seconds_bucket{method="GET",le="0.05"} 24054
+//	http_request_duration_seconds_bucket{method="GET",le="0.1"} 33444
+//	http_request_duration_seconds_bucket{method="GET",le="+Inf"} 144320
+//
+// The percentiles are interpolated from the bucket boundaries similarly to
+// histogram_quantile. Metrics are returned in the order of their first appearance.
+func ReadPrometheus(r io.Reader, percentiles []Percentile) ([]Metric, error) {
+	type series struct {
+		label   string
+		buckets map[float64]float64
+	}
+
+	var all []*series
+	index := map[string]*series{}
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, labels, value, err := parsePrometheusLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		if !strings.HasSuffix(name, "_bucket") {
+			continue
+		}
+
+		le, ok := labels["le"]
+		if !ok {
+			continue
+		}
+		upper, err := strconv.ParseFloat(le, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid le %q: %w", lineNumber, le, err)
+		}
+		delete(labels, "le")
+
+		label := formatPrometheusLabel(strings.TrimSuffix(name, "_bucket"), labels)
+		s, ok := index[label]
+		if !ok {
+			s = &series{label: label, buckets: map[float64]float64{}}
+			index[label] = s
+			all = append(all, s)
+		}
+		s.buckets[upper] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var metrics []Metric
+	for _, s := range all {
+		metrics = append(metrics, HistogramMetric(s.label, cumulativeBuckets(s.buckets), percentiles))
+	}
+	return metrics, nil
+}
+
+// cumulativeBuckets converts Prometheus cumulative counts per upper bound
+// into buckets.
+func cumulativeBuckets(counts map[float64]float64) []Bucket {
+	uppers := make([]float64, 0, len(counts))
+	for upper := range counts {
+		uppers = append(uppers, upper)
+	}
+	sort.Float64s(uppers)
+
+	var buckets []Bucket
+	lower, previous := 0.0, 0.0
+	for i, upper := range uppers {
+		if i == 0 && upper <= 0 {
+			lower = upper
+		}
+		cumulative := counts[upper]
+		buckets = append(buckets, Bucket{
+			Lower: lower,
+			Upper: upper,
+			Count: math.Max(cumulative-previous, 0),
+		})
+		lower, previous = upper, cumulative
+	}
+	return buckets
+}
+
+// parsePrometheusLine parses a sample line `name{labels} value [timestamp]`.
+func parsePrometheusLine(line string) (name string, labels map[string]string, value float64, err error) {
+	labels = map[string]string{}
+
+	end := strings.IndexAny(line, "{ \t")
+	if end < 0 {
+		return "", nil, 0, fmt.Errorf("missing value")
+	}
+	name, rest := line[:end], line[end:]
+
+	if strings.HasPrefix(rest, "{") {
+		rest, err = parsePrometheusLabels(rest[1:], labels)
+		if err != nil {
+			return "", nil, 0, err
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", nil, 0, fmt.Errorf("missing value")
+	}
+	value, err = strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", nil, 0, fmt.Errorf("invalid value %q: %w", fields[0], err)
+	}
+	return name, labels, value, nil
+}
+
+// parsePrometheusLabels parses `key="value",...}` into labels and
+// returns the text after the closing brace.
+func parsePrometheusLabels(s string, labels map[string]string) (rest string, err error) {
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if strings.HasPrefix(s, "}") {
+			return s[1:], nil
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 || len(s) < eq+2 || s[eq+1] != '"' {
+			return "", fmt.Errorf("invalid labels")
+		}
+		key := strings.TrimSpace(s[:eq])
+		s = s[eq+2:]
+
+		var value strings.Builder
+		closed := false
+		for i := 0; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '\\' && i+1 < len(s):
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(s[i])
+				}
+			case c == '"':
+				s = s[i+1:]
+				closed = true
+			default:
+				value.WriteByte(c)
+			}
+			if closed {
+				break
+			}
+		}
+		if !closed {
+			return "", fmt.Errorf("unterminated label value")
+		}
+		labels[key] = value.String()
+	}
+}
+
+// formatPrometheusLabel formats name and labels as `name{k="v",...}`.
+func formatPrometheusLabel(name string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return name
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(strconv.Quote(labels[k]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadPrometheus(t *testing.T) {
+	const input = `# HELP http_request_duration_seconds Request latency.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{method="GET",le="0.1"} 10
+http_request_duration_seconds_bucket{method="GET",le="0.2"} 30
+http_request_duration_seconds_bucket{method="GET",le="0.4"} 40
+http_request_duration_seconds_bucket{method="GET",le="+Inf"} 40
+http_request_duration_seconds_sum{method="GET"} 7.5
+http_request_duration_seconds_count{method="GET"} 40
+http_request_duration_seconds_bucket{le="1",method="POST"} 0
+http_request_duration_seconds_bucket{le="2",method="POST"} 4 1700000000000
+http_request_duration_seconds_bucket{le="+Inf",method="POST"} 5
+up 1
+`
+
+	percentiles := []Percentile{{Label: "p0", Value: 0}, {Label: "p50", Value: 0.5}, {Label: "p100", Value: 1}}
+	metrics, err := ReadPrometheus(strings.NewReader(input), percentiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Metric{
+		{Label: `http_request_duration_seconds{method="GET"}`, Values: []float64{0, 0.15, 0.4}},
+		{Label: `http_request_duration_seconds{method="POST"}`, Values: []float64{1, 1.625, 2}},
+	}
+	if len(metrics) != len(want) {
+		t.Fatalf("got %d metrics, want %d", len(metrics), len(want))
+	}
+	for i, metric := range metrics {
+		if metric.Label != want[i].Label {
+			t.Errorf("got label %q, want %q", metric.Label, want[i].Label)
+		}
+		for k, v := range metric.Values {
+			if math.Abs(v-want[i].Values[k]) > 1e-9 {
+				t.Errorf("%s %s: got %v, want %v", metric.Label, percentiles[k].Label, v, want[i].Values[k])
+			}
+		}
+	}
+}
+
+func TestReadPrometheusInvalid(t *testing.T) {
+	tests := []string{
+		`x_bucket{le="0.1"}`,
+		`x_bucket{le="0.1"} abc`,
+		`x_bucket{le="abc"} 1`,
+		`x_bucket{le="0.1} 1`,
+		`x_bucket{le} 1`,
+	}
+	for _, input := range tests {
+		if _, err := ReadPrometheus(strings.NewReader(input), DefaultPercentiles()); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestParsePrometheusLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		name   string
+		labels map[string]string
+		value  float64
+	}{
+		{`up 1`, "up", map[string]string{}, 1},
+		{`up{} 0.5 1700000000000`, "up", map[string]string{}, 0.5},
+		{`x{a="1", b="two"} 3`, "x", map[string]string{"a": "1", "b": "two"}, 3},
+		{`x{a="q\"uo\\te\n"} 4`, "x", map[string]string{"a": "q\"uo\\te\n"}, 4},
+		{`x{a="}"} 5`, "x", map[string]string{"a": "}"}, 5},
+		{`x{le="+Inf",} 6`, "x", map[string]string{"le": "+Inf"}, 6},
+	}
+	for _, test := range tests {
+		name, labels, value, err := parsePrometheusLine(test.line)
+		if err != nil {
+			t.Errorf("%q: %v", test.line, err)
+			continue
+		}
+		if name != test.name || !reflect.DeepEqual(labels, test.labels) || value != test.value {
+			t.Errorf("%q: got (%q, %v, %v), want (%q, %v, %v)",
+				test.line, name, labels, value, test.name, test.labels, test.value)
+		}
+	}
+}
+
+func TestCumulativeBuckets(t *testing.T) {
+	got := cumulativeBuckets(map[float64]float64{
+		-1:          2,
+		1:           5,
+		math.Inf(1): 5,
+	})
+	want := []Bucket{
+		{Lower: -1, Upper: -1, Count: 2},
+		{Lower: -1, Upper: 1, Count: 3},
+		{Lower: 1, Upper: math.Inf(1), Count: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %v\nwant %v", got, want)
+	}
+}
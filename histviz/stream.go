@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stream collects samples over a sliding time window and calculates
+// the percentiles for each metric.
+//
+// Samples are read as lines of `[label] value`, samples without a label
+// are added to the metric "stream".
+type Stream struct {
+	Percentiles []Percentile
+	Window      time.Duration
+
+	mu      sync.Mutex
+	metrics []*streamMetric
+	index   map[string]*streamMetric
+	span    Range
+}
+
+// streamSlices is the number of digests the window is split into.
+const streamSlices = 10
+
+// streamCompression is the t-digest compression for each slice.
+const streamCompression = 100
+
+type streamMetric struct {
+	label  string
+	slices []streamSlice
+}
+
+type streamSlice struct {
+	start  time.Time
+	digest *TDigest
+}
+
+// NewStream creates a stream calculating the percentiles over window.
+func NewStream(percentiles []Percentile, window time.Duration) *Stream {
+	return &Stream{
+		Percentiles: percentiles,
+		Window:      window,
+		index:       map[string]*streamMetric{},
+		span:        Range{Min: math.NaN(), Max: math.NaN()},
+	}
+}
+
+// Add adds a sample for the metric.
+func (stream *Stream) Add(label string, value float64, now time.Time) {
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	metric, ok := stream.index[label]
+	if !ok {
+		metric = &streamMetric{label: label}
+		stream.index[label] = metric
+		stream.metrics = append(stream.metrics, metric)
+	}
+
+	start := now.Truncate(stream.Window / streamSlices)
+	if n := len(metric.slices); n == 0 || !metric.slices[n-1].start.Equal(start) {
+		metric.slices = append(metric.slices, streamSlice{
+			start:  start,
+			digest: NewTDigest(streamCompression),
+		})
+	}
+	metric.slices[len(metric.slices)-1].digest.Add(value)
+}
+
+// Snapshot calculates the percentiles of the samples inside the window.
+//
+// The range expands immediately to fit new values, but shrinks
+// gradually so that the colors don't jump around between snapshots.
+func (stream *Stream) Snapshot(now time.Time) *Data {
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	data := &Data{Percentiles: stream.Percentiles}
+	for _, metric := range stream.metrics {
+		// drop slices that have left the window
+		expired := 0
+		for expired < len(metric.slices) && now.Sub(metric.slices[expired].start) > stream.Window {
+			expired++
+		}
+		metric.slices = metric.slices[expired:]
+
+		merged := NewTDigest(streamCompression)
+		for _, slice := range metric.slices {
+			merged.Merge(slice.digest)
+		}
+
+		values := make([]float64, len(stream.Percentiles))
+		for i, p := range stream.Percentiles {
+			values[i] = merged.Quantile(p.Value)
+		}
+		data.Metrics = append(data.Metrics, Metric{Label: metric.label, Values: values})
+	}
+
+	data.UpdateRange()
+	if data.Range != (Range{}) {
+		if math.IsNaN(stream.span.Min) {
+			stream.span = data.Range
+		} else {
+			const shrink = 0.1
+			stream.span.Min = min(data.Range.Min, lerp(shrink, stream.span.Min, data.Range.Min))
+			stream.span.Max = max(data.Range.Max, lerp(shrink, stream.span.Max, data.Range.Max))
+		}
+		data.Range = stream.span
+	}
+	return data
+}
+
+// Read adds samples from r until it's closed.
+func (stream *Stream) Read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		label, value, ok := parseSampleLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		stream.Add(label, value, time.Now())
+	}
+	return scanner.Err()
+}
+
+// Listen accepts connections on addr and reads samples from them.
+// Addresses containing a path separator are treated as unix sockets.
+func (stream *Stream) Listen(addr string) error {
+	network := "tcp"
+	if strings.ContainsRune(addr, os.PathSeparator) {
+		network = "unix"
+	}
+
+	listener, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = listener.Close() }()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer func() { _ = conn.Close() }()
+			if err := stream.Read(conn); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}()
+	}
+}
+
+// parseSampleLine parses `[label] value`, the label may contain spaces.
+func parseSampleLine(line string) (label string, value float64, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", 0, false
+	}
+
+	label = "stream"
+	text := line
+	if i := strings.LastIndexAny(line, " \t"); i >= 0 {
+		label, text = strings.TrimSpace(line[:i]), line[i+1:]
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return label, value, true
+}
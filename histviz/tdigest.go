@@ -0,0 +1,132 @@
+package main
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// TDigest is a merging t-digest for estimating quantiles of a stream
+// of samples using bounded memory.
+//
+// See "Computing Extremely Accurate Quantiles Using t-Digests" by
+// Ted Dunning and Otmar Ertl.
+type TDigest struct {
+	// Compression controls the accuracy and size of the digest,
+	// roughly the number of centroids kept.
+	Compression float64
+
+	centroids []centroid
+	buffer    []float64
+	count     float64
+	min, max  float64
+}
+
+type centroid struct {
+	mean, weight float64
+}
+
+// NewTDigest creates a new digest with the specified compression.
+func NewTDigest(compression float64) *TDigest {
+	return &TDigest{
+		Compression: compression,
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+// Count returns the number of samples added.
+func (d *TDigest) Count() float64 { return d.count + float64(len(d.buffer)) }
+
+// Add adds a sample to the digest.
+func (d *TDigest) Add(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+	d.buffer = append(d.buffer, v)
+	d.min = min(d.min, v)
+	d.max = max(d.max, v)
+	if len(d.buffer) >= int(d.Compression)*4 {
+		d.compress()
+	}
+}
+
+// Merge adds all samples from other to the digest.
+func (d *TDigest) Merge(other *TDigest) {
+	other.compress()
+	if other.count == 0 {
+		return
+	}
+	d.compress()
+	d.centroids = append(d.centroids, other.centroids...)
+	d.count += other.count
+	d.min = min(d.min, other.min)
+	d.max = max(d.max, other.max)
+	d.merge()
+}
+
+// Quantile returns the estimated value at quantile q in range 0..1,
+// NaN when the digest is empty.
+func (d *TDigest) Quantile(q float64) float64 {
+	d.compress()
+	switch len(d.centroids) {
+	case 0:
+		return math.NaN()
+	case 1:
+		return d.centroids[0].mean
+	}
+
+	target := clamp(q, 0, 1) * d.count
+
+	// the centroid mean is assumed to be at the center of its weight
+	cumulative, previousCenter, previousMean := 0.0, 0.0, d.min
+	for _, c := range d.centroids {
+		center := cumulative + c.weight/2
+		if target < center {
+			return lerp(clamp(invlerp(target, previousCenter, center), 0, 1), previousMean, c.mean)
+		}
+		cumulative += c.weight
+		previousCenter, previousMean = center, c.mean
+	}
+	return lerp(clamp(invlerp(target, previousCenter, d.count), 0, 1), previousMean, d.max)
+}
+
+// compress merges the buffered samples into centroids.
+func (d *TDigest) compress() {
+	if len(d.buffer) == 0 {
+		return
+	}
+	for _, v := range d.buffer {
+		d.centroids = append(d.centroids, centroid{mean: v, weight: 1})
+	}
+	d.count += float64(len(d.buffer))
+	d.buffer = d.buffer[:0]
+	d.merge()
+}
+
+// merge combines neighbouring centroids while keeping them smaller
+// near the tails, where accuracy matters the most.
+func (d *TDigest) merge() {
+	slices.SortFunc(d.centroids, func(a, b centroid) int { return cmp.Compare(a.mean, b.mean) })
+
+	merged := d.centroids[:1]
+	current := &merged[0]
+	soFar := 0.0
+	for _, next := range d.centroids[1:] {
+		proposed := current.weight + next.weight
+		q0 := soFar / d.count
+		q2 := (soFar + proposed) / d.count
+		limit := 4 * d.count * min(q0*(1-q0), q2*(1-q2)) / d.Compression
+
+		if proposed <= limit {
+			current.mean += (next.mean - current.mean) * next.weight / proposed
+			current.weight = proposed
+			continue
+		}
+
+		soFar += current.weight
+		merged = append(merged, next)
+		current = &merged[len(merged)-1]
+	}
+	d.centroids = merged
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestTDigestExact(t *testing.T) {
+	d := NewTDigest(100)
+	for _, v := range []float64{5, 1, 4, 2, 3} {
+		d.Add(v)
+	}
+
+	tests := []struct {
+		q, want float64
+	}{
+		{0, 1},
+		{0.3, 2},
+		{0.5, 3},
+		{0.7, 4},
+		{1, 5},
+		{-1, 1},
+		{2, 5},
+	}
+	for _, test := range tests {
+		if got := d.Quantile(test.q); got != test.want {
+			t.Errorf("Quantile(%v) = %v, want %v", test.q, got, test.want)
+		}
+	}
+}
+
+func TestTDigestEmpty(t *testing.T) {
+	d := NewTDigest(100)
+	d.Add(math.NaN())
+	if got := d.Quantile(0.5); !math.IsNaN(got) {
+		t.Errorf("empty digest: got %v, want NaN", got)
+	}
+
+	d.Add(7)
+	if got := d.Quantile(0.9); got != 7 {
+		t.Errorf("single sample: got %v, want 7", got)
+	}
+}
+
+func TestTDigestUniform(t *testing.T) {
+	const n = 100000
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	// split the samples into two digests to exercise Merge
+	a, b := NewTDigest(100), NewTDigest(100)
+	for i, v := range rng.Perm(n) {
+		if i%3 == 0 {
+			a.Add(float64(v))
+		} else {
+			b.Add(float64(v))
+		}
+	}
+	a.Merge(b)
+
+	if a.Count() != n {
+		t.Fatalf("got count %v, want %v", a.Count(), n)
+	}
+
+	tests := []struct {
+		q, tolerance float64
+	}{
+		{0, 0},
+		{0.001, 0.0005},
+		{0.01, 0.001},
+		{0.25, 0.01},
+		{0.5, 0.01},
+		{0.75, 0.01},
+		{0.99, 0.001},
+		{0.999, 0.0005},
+		{1, 0},
+	}
+	for _, test := range tests {
+		got := a.Quantile(test.q)
+		want := test.q * (n - 1)
+		if math.Abs(got-want) > test.tolerance*n {
+			t.Errorf("Quantile(%v) = %v, want %v ± %v", test.q, got, want, test.tolerance*n)
+		}
+	}
+}
@@ -1,17 +1,105 @@
 package main
 
 import (
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/f32"
 	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/widget/material"
 )
 
+// TrendPlot draws a single percentile across all the metrics,
+// e.g. to show how p99 changes over time.
 type TrendPlot struct {
-	Data *Data
-	Row  int
+	Theme   *material.Theme
+	Palette *Palette
+	Data    *Data
+	// Comparison, when set, draws the base values as a reference and
+	// colors the values by the change. Data should be Comparison.Head.
+	Comparison *Comparison
+
+	// Row is the percentile to draw.
+	Row int
+	// Col is the highlighted metric.
+	Col int
 }
 
 func (plot *TrendPlot) Layout(gtx layout.Context) layout.Dimensions {
 	gtx.Constraints.Min = gtx.Constraints.Max
 	size := gtx.Constraints.Max
 
+	data := plot.Data
+	if plot.Row < 0 || plot.Row >= len(data.Percentiles) || len(data.Metrics) == 0 {
+		return layout.Dimensions{Size: size}
+	}
+
+	xrange := Range{Min: -0.5, Max: float64(len(data.Metrics)) - 0.5}
+	span := valueRange(data.Range)
+	if plot.Comparison != nil {
+		span = valueRange(plot.Comparison.Range())
+	}
+	yticks, yrange := niceTicks(span, 4)
+	area := NewPlotArea(gtx, size, xrange, yrange)
+	if area.Empty() {
+		return layout.Dimensions{Size: size}
+	}
+
+	area.HorizontalGrid(gtx, plot.Theme, yticks)
+	area.Title(gtx, plot.Theme, data.Percentiles[plot.Row].Label)
+
+	if plot.Col >= 0 && plot.Col < len(data.Metrics) {
+		x0 := int(area.X.ToScreen(float64(plot.Col) - 0.5))
+		x1 := int(area.X.ToScreen(float64(plot.Col) + 0.5))
+		paint.FillShape(gtx.Ops, plotGridColor, clip.Rect{
+			Min: image.Pt(x0, area.Rect.Min.Y),
+			Max: image.Pt(max(x1, x0+1), area.Rect.Max.Y),
+		}.Op())
+		DrawText(gtx, plot.Theme, image.Pt((x0+x1)/2, area.Rect.Max.Y+gtx.Dp(2)), layout.N,
+			data.Metrics[plot.Col].Label)
+	}
+
+	if plot.Comparison != nil {
+		_, base := plot.trend(area, plot.Comparison.Base)
+		area.Polyline(gtx, base, plotFocusColor)
+	}
+	values, points := plot.trend(area, data)
+	area.Polyline(gtx, points, plotLineColor)
+
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		if i == plot.Col {
+			area.Dot(gtx, points[i], plotDotRadius*2, plotFocusColor)
+		}
+		area.Dot(gtx, points[i], plotDotRadius, plot.color(i, v))
+	}
+
 	return layout.Dimensions{Size: size}
 }
+
+// trend returns the values of the percentile Row for each metric.
+func (plot *TrendPlot) trend(area PlotArea, data *Data) ([]float64, []f32.Point) {
+	values := make([]float64, len(data.Metrics))
+	points := make([]f32.Point, len(data.Metrics))
+	for i := range data.Metrics {
+		values[i] = math.NaN()
+		if metric := &data.Metrics[i]; plot.Row < len(metric.Values) {
+			values[i] = metric.Values[plot.Row]
+		}
+		points[i] = area.Pt(float64(i), values[i])
+	}
+	return values, points
+}
+
+// color returns the color for the value of the specified metric.
+func (plot *TrendPlot) color(col int, value float64) color.NRGBA {
+	if c := plot.Comparison; c != nil {
+		return plot.Palette.Color(c.Delta.Metrics[col].Values[plot.Row], c.Delta.Range)
+	}
+	return plot.Palette.Color(value, plot.Data.Range)
+}
@@ -0,0 +1,64 @@
+package lay
+
+import (
+	"image"
+
+	"gioui.org/layout"
+)
+
+// FlexH lays out the children horizontally, separated by Gap.
+// The zero Gap is the Default spacing, use None for no gap.
+type FlexH struct {
+	Gap       Scale
+	Alignment layout.Alignment
+	Children  []Node
+}
+
+func (flex FlexH) Layout(th *Theme, gtx layout.Context) layout.Dimensions {
+	return layoutFlex(th, gtx, layout.Horizontal, flex.Gap, flex.Alignment, flex.Children)
+}
+
+// FlexV lays out the children vertically, separated by Gap.
+// The zero Gap is the Default spacing, use None for no gap.
+type FlexV struct {
+	Gap       Scale
+	Alignment layout.Alignment
+	Children  []Node
+}
+
+func (flex FlexV) Layout(th *Theme, gtx layout.Context) layout.Dimensions {
+	return layoutFlex(th, gtx, layout.Vertical, flex.Gap, flex.Alignment, flex.Children)
+}
+
+// Grow makes the child of FlexH or FlexV take a share of the
+// remaining space proportional to Weight.
+type Grow struct {
+	Weight float32
+	Child  Node
+}
+
+func (grow Grow) Layout(th *Theme, gtx layout.Context) layout.Dimensions {
+	return layoutNode(th, gtx, grow.Child)
+}
+
+func layoutFlex(th *Theme, gtx layout.Context, axis layout.Axis, gap Scale, alignment layout.Alignment, nodes []Node) layout.Dimensions {
+	gapSize := axis.Convert(image.Pt(gtx.Dp(th.Space(gap)), 0))
+
+	children := make([]layout.FlexChild, 0, 2*len(nodes))
+	for i, node := range nodes {
+		if i > 0 && gapSize != (image.Point{}) {
+			children = append(children, layout.Rigid(spacer(gapSize)))
+		}
+
+		w := func(gtx layout.Context) layout.Dimensions {
+			return layoutNode(th, gtx, node)
+		}
+		if grow, ok := node.(Grow); ok {
+			children = append(children, layout.Flexed(grow.Weight, w))
+		} else {
+			children = append(children, layout.Rigid(w))
+		}
+	}
+
+	return layout.Flex{Axis: axis, Alignment: alignment}.Layout(gtx, children...)
+}
@@ -0,0 +1,90 @@
+// Package lay describes user interfaces as a tree of value-typed nodes,
+// where the sizes are steps on the modular scale of a Theme:
+//
+//	Box{Padding{Child: FlexH{Children: []Node{
+//		Icon{},
+//		Grow{1, FlexV{Gap: None, Children: []Node{
+//			FlexH{Gap: Small, Children: []Node{
+//				Label{Title, "Messages"},
+//				Color{red, Text{Small, "| Global"}},
+//			}},
+//			Text{Default, "lorem"},
+//		}}},
+//		Icon{Icon: dragIcon},
+//	}}}}
+//
+// The zero Scale is Default, hence gaps and paddings use the Default
+// spacing unless they are set to None.
+package lay
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+)
+
+// Node is an element in the layout tree.
+type Node interface {
+	Layout(th *Theme, gtx layout.Context) layout.Dimensions
+}
+
+// Func adapts a function to a Node.
+type Func func(th *Theme, gtx layout.Context) layout.Dimensions
+
+// Layout calls fn.
+func (fn Func) Layout(th *Theme, gtx layout.Context) layout.Dimensions {
+	return fn(th, gtx)
+}
+
+// Widget adapts a regular widget to a Node.
+type Widget layout.Widget
+
+// Layout calls w, ignoring the theme.
+func (w Widget) Layout(th *Theme, gtx layout.Context) layout.Dimensions {
+	return w(gtx)
+}
+
+// Box fills the background behind the child.
+type Box struct {
+	Child Node
+}
+
+func (box Box) Layout(th *Theme, gtx layout.Context) layout.Dimensions {
+	return layout.Background{}.Layout(gtx,
+		func(gtx layout.Context) layout.Dimensions {
+			defer clip.Rect{Max: gtx.Constraints.Min}.Push(gtx.Ops).Pop()
+			paint.Fill(gtx.Ops, th.Bg)
+			return layout.Dimensions{Size: gtx.Constraints.Min}
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layoutNode(th, gtx, box.Child)
+		})
+}
+
+// Color changes the foreground color of the child.
+type Color struct {
+	Fg    color.NRGBA
+	Child Node
+}
+
+func (c Color) Layout(th *Theme, gtx layout.Context) layout.Dimensions {
+	return layoutNode(th.Colorize(c.Fg, th.Bg), gtx, c.Child)
+}
+
+// layoutNode lays out the node, treating nil as an empty node.
+func layoutNode(th *Theme, gtx layout.Context, node Node) layout.Dimensions {
+	if node == nil {
+		return layout.Dimensions{Size: gtx.Constraints.Min}
+	}
+	return node.Layout(th, gtx)
+}
+
+// spacer returns an empty widget of the specified size.
+func spacer(size image.Point) layout.Widget {
+	return func(gtx layout.Context) layout.Dimensions {
+		return layout.Dimensions{Size: gtx.Constraints.Constrain(size)}
+	}
+}
@@ -2,42 +2,46 @@ package lay
 
 import "gioui.org/layout"
 
+// Padding adds space around the child, the zero value uses the Default
+// step on every side and None removes the space.
 type Padding struct {
 	N, E, S, W Scale
+	Child      Node
 }
 
-func (p Padding) Layout(gtx layout.Context, w layout.Widget) layout.Dimensions {
-	return layout.Dimensions{}
+// Inset returns padding with the same step on every side.
+func Inset(s Scale, child Node) Padding {
+	return Padding{N: s, E: s, S: s, W: s, Child: child}
+}
+
+func (p Padding) Layout(th *Theme, gtx layout.Context) layout.Dimensions {
+	return layout.Inset{
+		Top:    th.Space(p.N),
+		Right:  th.Space(p.E),
+		Bottom: th.Space(p.S),
+		Left:   th.Space(p.W),
+	}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layoutNode(th, gtx, p.Child)
+	})
 }
 
 type Scroll struct {
 	Position int
 }
 
+// Stack lays out the children on top of each other, aligned by
+// Alignment; it's as large as the largest child.
 type Stack struct {
-	Gap Scale
-}
-
-func (stack Stack) Layout(gtx layout.Context, w layout.Widget) layout.Dimensions {
-	return layout.Dimensions{}
+	Alignment layout.Direction
+	Children  []Node
 }
 
-/*
-
-Box{
-	FlexH{
-		Icon{},
-		FlexV{
-			Gap: Small,
-			FlexH{
-				Gap: Small,
-				Text{Big, "Messages"},
-				Color{Red, Text{Small, "| Global"}},
-			},
-			Text{Default, "lorem"},
-		},
-		DragIcon{},
+func (stack Stack) Layout(th *Theme, gtx layout.Context) layout.Dimensions {
+	children := make([]layout.StackChild, 0, len(stack.Children))
+	for _, child := range stack.Children {
+		children = append(children, layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+			return layoutNode(th, gtx, child)
+		}))
 	}
+	return layout.Stack{Alignment: stack.Alignment}.Layout(gtx, children...)
 }
-
-*/
@@ -0,0 +1,59 @@
+package lay
+
+import (
+	"image"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"gioui.org/widget"
+)
+
+// Text displays a string with the text size at step Size.
+type Text struct {
+	Size Scale
+	Text string
+}
+
+func (t Text) Layout(th *Theme, gtx layout.Context) layout.Dimensions {
+	return layoutText(th, gtx, th.TextStyleAt(t.Size), t.Text)
+}
+
+// Label displays a string in the style of Role.
+type Label struct {
+	Role TextRole
+	Text string
+}
+
+func (l Label) Layout(th *Theme, gtx layout.Context) layout.Dimensions {
+	return layoutText(th, gtx, th.Style(l.Role), l.Text)
+}
+
+func layoutText(th *Theme, gtx layout.Context, style TextStyle, txt string) layout.Dimensions {
+	macro := op.Record(gtx.Ops)
+	paint.ColorOp{Color: th.Fg}.Add(gtx.Ops)
+	material := macro.Stop()
+
+	return widget.Label{
+		LineHeight: style.LineHeight,
+	}.Layout(gtx, th.Shaper, font.Font{Weight: style.Weight}, style.Size, txt, material)
+}
+
+// Icon displays a square icon with the height of a line of text at step
+// Size. A nil Icon leaves an empty space.
+type Icon struct {
+	Size Scale
+	Icon *widget.Icon
+}
+
+func (icon Icon) Layout(th *Theme, gtx layout.Context) layout.Dimensions {
+	px := gtx.Sp(th.LineHeightAt(icon.Size))
+	size := gtx.Constraints.Constrain(image.Pt(px, px))
+	if icon.Icon == nil {
+		return layout.Dimensions{Size: size}
+	}
+
+	gtx.Constraints = layout.Exact(size)
+	return icon.Icon.Layout(gtx, th.Fg)
+}
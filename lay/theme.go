@@ -4,16 +4,20 @@ import (
 	"image/color"
 	"math"
 
+	"gioui.org/font"
 	"gioui.org/text"
 	"gioui.org/unit"
+	"gioui.org/widget/material"
 )
 
 type Theme struct {
 	Shaper *text.Shaper
 
+	// Base and BaseRatio define the modular scale for spacing.
 	Base      unit.Sp
 	BaseRatio ScaleRatio
 
+	// TextSize and TextRatio define the modular scale for text.
 	TextSize   unit.Sp
 	LineHeight unit.Sp
 	TextRatio  ScaleRatio
@@ -28,16 +32,14 @@ func NewTheme(fontCollection []text.FontFace) *Theme {
 		Shaper: text.NewShaper(text.WithCollection(fontCollection)),
 	}
 
-	th.Palette = Palette{
-		Fg: color.NRGBA{A: 0xFF},
-		Bg: color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
-	}
+	th.Palette = Light
 
 	th.Base = unit.Sp(4)
 	th.BaseRatio = 1.5
 
 	th.TextSize = unit.Sp(16)
-	th.LineHeight = unit.Sp(18)
+	// 20sp keeps the default lines on the 4sp spacing grid.
+	th.LineHeight = unit.Sp(20)
 	th.TextRatio = 1.4
 
 	// 38dp is on the lower end of possible finger size.
@@ -61,9 +63,148 @@ func (th *Theme) Colorize(fg, bg color.NRGBA) *Theme {
 	return &scaled
 }
 
+// WithPalette returns the theme with all the colors replaced,
+// e.g. th.WithPalette(Dark).
+func (th *Theme) WithPalette(palette Palette) *Theme {
+	colored := *th
+	colored.Palette = palette
+	return &colored
+}
+
+// Space returns the spacing at step s, None is no space.
+func (th *Theme) Space(s Scale) unit.Dp {
+	if s == None {
+		return 0
+	}
+	return unit.Dp(s.Value(th.Base, th.BaseRatio))
+}
+
+// TextSizeAt returns the text size at step s.
+func (th *Theme) TextSizeAt(s Scale) unit.Sp {
+	return s.Value(th.TextSize, th.TextRatio)
+}
+
+// LineHeightAt returns the line height for text at step s. It keeps the
+// proportion of LineHeight to TextSize, rounded up to a multiple of Base.
+func (th *Theme) LineHeightAt(s Scale) unit.Sp {
+	height := th.LineHeight
+	if s != Default && th.TextSize > 0 {
+		height = th.TextSizeAt(s) * th.LineHeight / th.TextSize
+	}
+	if th.Base <= 0 {
+		return height
+	}
+	// keep the lines on the spacing grid, the tolerance avoids
+	// rounding up float errors after Scale
+	steps := math.Ceil(float64(height/th.Base) - 1e-3)
+	return unit.Sp(steps) * th.Base
+}
+
+// TextStyleAt returns the style for regular text at step s.
+func (th *Theme) TextStyleAt(s Scale) TextStyle {
+	return TextStyle{
+		Size:       th.TextSizeAt(s),
+		LineHeight: th.LineHeightAt(s),
+	}
+}
+
+// Style returns the style for the text role.
+func (th *Theme) Style(role TextRole) TextStyle {
+	style := th.TextStyleAt(role.Step())
+	style.Weight = role.Weight()
+	return style
+}
+
+// Material returns a material theme matching th, so that the existing
+// widgets can be used together with lay. It parses the material icons,
+// hence the result should be reused across frames.
+func (th *Theme) Material() *material.Theme {
+	mat := material.NewTheme()
+	mat.Shaper = th.Shaper
+	mat.TextSize = th.TextSize
+	mat.FingerSize = th.FingerSize
+	mat.Palette = material.Palette{
+		Fg:         th.Fg,
+		Bg:         th.Bg,
+		ContrastBg: th.Accent,
+		ContrastFg: th.Bg,
+	}
+	return mat
+}
+
 type Palette struct {
 	Fg color.NRGBA
 	Bg color.NRGBA
+	// Muted is for secondary content.
+	Muted color.NRGBA
+	// Accent is for drawing attention, such as active controls.
+	Accent color.NRGBA
+}
+
+var (
+	Light = Palette{
+		Fg:     color.NRGBA{A: 0xFF},
+		Bg:     color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		Muted:  color.NRGBA{R: 0x6B, G: 0x6B, B: 0x6B, A: 0xFF},
+		Accent: color.NRGBA{R: 0x3F, G: 0x51, B: 0xB5, A: 0xFF},
+	}
+	Dark = Palette{
+		Fg:     color.NRGBA{R: 0xE8, G: 0xE8, B: 0xE8, A: 0xFF},
+		Bg:     color.NRGBA{R: 0x12, G: 0x12, B: 0x12, A: 0xFF},
+		Muted:  color.NRGBA{R: 0x9A, G: 0x9A, B: 0x9A, A: 0xFF},
+		Accent: color.NRGBA{R: 0x8C, G: 0x9E, B: 0xFF, A: 0xFF},
+	}
+)
+
+// TextStyle is a resolved text style.
+type TextStyle struct {
+	Size       unit.Sp
+	LineHeight unit.Sp
+	Weight     font.Weight
+}
+
+// Material returns a material label using the style.
+func (style TextStyle) Material(th *material.Theme, txt string) material.LabelStyle {
+	label := material.Label(th, style.Size, txt)
+	label.Font.Weight = style.Weight
+	label.LineHeight = style.LineHeight
+	return label
+}
+
+// TextRole is a named text style.
+type TextRole byte
+
+const (
+	Caption TextRole = iota
+	Body
+	Title
+	Display
+)
+
+// Step returns the step on the text scale for the role.
+func (role TextRole) Step() Scale {
+	switch role {
+	case Caption:
+		return Small
+	case Title:
+		return Big
+	case Display:
+		return Biggest
+	default:
+		return Default
+	}
+}
+
+// Weight returns the font weight for the role.
+func (role TextRole) Weight() font.Weight {
+	switch role {
+	case Title:
+		return font.SemiBold
+	case Display:
+		return font.Bold
+	default:
+		return font.Normal
+	}
 }
 
 type Scale int8
@@ -73,11 +214,22 @@ type ScaleRatio = unit.Sp
 const (
 	None Scale = -0x7f
 
-	Smaller Scale = -2
-	Small   Scale = -1
-	Default Scale = 0
-	Big     Scale = 1
-	Bigger  Scale = 1
+	Smallest Scale = -3
+	Smaller  Scale = -2
+	Small    Scale = -1
+	Default  Scale = 0
+	Big      Scale = 1
+	Bigger   Scale = 2
+	Biggest  Scale = 3
+)
+
+// Spacing tokens for the common cases.
+const (
+	SpaceInline  = Smaller // between an icon and its label
+	SpaceItem    = Small   // between items in a list
+	SpaceContent = Default // around the content of a control
+	SpaceGroup   = Big     // between groups of controls
+	SpaceSection = Bigger  // between sections of a screen
 )
 
 func (s Scale) Value(base unit.Sp, ratio ScaleRatio) unit.Sp {
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Form edits the exported fields of a struct:
+//
+//   - fields implementing Spinnable get a spin box,
+//   - numeric fields with a range get a slider,
+//   - booleans get a toggle,
+//   - nested structs and arrays are expanded.
+//
+// The fields are configured with a `form` struct tag, e.g.
+//
+//	Level float32 `form:"label=Level,min=-1,max=1,step=0.1"`
+//
+// where "labels=A|B" names the elements of an array and `form:"-"`
+// skips the field. Other fields are ignored.
+type Form struct {
+	Fields []*FormField
+	Gap    unit.Dp
+}
+
+// NewForm creates a form for the struct pointed to by target.
+func NewForm(target any) *Form {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("form: expected pointer to struct, got %T", target))
+	}
+
+	form := &Form{}
+	form.addStruct("", v.Elem())
+	return form
+}
+
+func (form *Form) addStruct(prefix string, v reflect.Value) {
+	t := v.Type()
+	for i := range t.NumField() {
+		def := t.Field(i)
+		if !def.IsExported() {
+			continue
+		}
+		tag := parseFormTag(def.Tag.Get("form"))
+		if tag.skip {
+			continue
+		}
+
+		label, ok := tag.values["label"]
+		if !ok {
+			label = def.Name
+		}
+		form.addValue(prefix+def.Name, label, tag, v.Field(i))
+	}
+}
+
+func (form *Form) addValue(path, label string, tag formTag, v reflect.Value) {
+	if _, ok := v.Interface().(fmt.Stringer); ok && v.MethodByName("Options").IsValid() {
+		form.Fields = append(form.Fields, &FormField{Path: path, Label: label, kind: fieldEnum, value: v})
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		form.addStruct(path+".", v)
+
+	case reflect.Array:
+		labels := strings.Split(tag.values["labels"], "|")
+		for i := range v.Len() {
+			elemLabel := label + " " + strconv.Itoa(i+1)
+			if i < len(labels) && labels[i] != "" {
+				elemLabel = labels[i]
+			}
+			form.addValue(fmt.Sprintf("%s[%d]", path, i), elemLabel, tag, v.Index(i))
+		}
+
+	case reflect.Bool:
+		form.Fields = append(form.Fields, &FormField{Path: path, Label: label, kind: fieldBool, value: v})
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		low, lowErr := strconv.ParseFloat(tag.values["min"], 64)
+		high, highErr := strconv.ParseFloat(tag.values["max"], 64)
+		if lowErr != nil || highErr != nil || high <= low {
+			// sliders need a range
+			return
+		}
+		step, _ := strconv.ParseFloat(tag.values["step"], 64)
+		form.Fields = append(form.Fields, &FormField{
+			Path: path, Label: label, kind: fieldNumber, value: v,
+			min: low, max: high, step: step,
+		})
+	}
+}
+
+// Layout lays out all the fields below each other, sharing the height
+// equally and separated by Gap.
+func (form *Form) Layout(th *material.Theme, gtx layout.Context) layout.Dimensions {
+	children := make([]layout.FlexChild, 0, 2*len(form.Fields))
+	for i, field := range form.Fields {
+		if i > 0 {
+			children = append(children, layout.Rigid(layout.Spacer{Height: form.Gap}.Layout))
+		}
+		children = append(children, layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return field.Layout(th, gtx)
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+type fieldKind byte
+
+const (
+	fieldEnum = fieldKind(iota)
+	fieldNumber
+	fieldBool
+)
+
+// FormField edits a single value.
+type FormField struct {
+	Path  string
+	Label string
+
+	kind  fieldKind
+	value reflect.Value
+
+	// for numbers
+	min, max, step float64
+
+	next, prev widget.Clickable
+	slider     widget.Float
+	toggle     widget.Bool
+}
+
+// Layout lays out the widget for editing the field.
+func (field *FormField) Layout(th *material.Theme, gtx layout.Context) layout.Dimensions {
+	switch field.kind {
+	case fieldEnum:
+		if field.prev.Clicked(gtx) {
+			field.spin(-1)
+		}
+		if field.next.Clicked(gtx) {
+			field.spin(1)
+		}
+		return layoutSpin(th, gtx, &field.next, &field.prev, field.label(field.value.Interface().(fmt.Stringer).String()))
+
+	case fieldNumber:
+		field.slider.Value = float32((field.number() - field.min) / (field.max - field.min))
+		if field.slider.Update(gtx) {
+			field.setNumber(field.min + float64(field.slider.Value)*(field.max-field.min))
+		}
+		return layout.W.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(material.Body1(th, field.label(strconv.FormatFloat(field.number(), 'g', 3, 64))).Layout),
+				layout.Flexed(1, material.Slider(th, &field.slider).Layout),
+			)
+		})
+
+	case fieldBool:
+		field.toggle.Value = field.value.Bool()
+		if field.toggle.Update(gtx) {
+			field.value.SetBool(field.toggle.Value)
+		}
+		return layout.W.Layout(gtx, material.CheckBox(th, &field.toggle, field.Label).Layout)
+	}
+	return layout.Dimensions{}
+}
+
+func (field *FormField) label(value string) string {
+	if field.Label == "" {
+		return value
+	}
+	return field.Label + " " + value
+}
+
+// spin changes the value to the next or previous option, wrapping around.
+func (field *FormField) spin(offset int) {
+	options := field.value.MethodByName("Options").Call(nil)[0]
+	if options.Len() == 0 {
+		return
+	}
+
+	current := -1
+	for i := range options.Len() {
+		if options.Index(i).Equal(field.value) {
+			current = i
+			break
+		}
+	}
+	if current < 0 { // didn't find the current value in the options list
+		field.value.Set(options.Index(0))
+		return
+	}
+
+	n := options.Len()
+	field.value.Set(options.Index(((current+offset)%n + n) % n))
+}
+
+func (field *FormField) number() float64 {
+	switch {
+	case field.value.CanInt():
+		return float64(field.value.Int())
+	case field.value.CanUint():
+		return float64(field.value.Uint())
+	default:
+		return field.value.Float()
+	}
+}
+
+func (field *FormField) setNumber(v float64) {
+	if field.step > 0 {
+		v = field.min + math.Round((v-field.min)/field.step)*field.step
+	}
+	v = min(max(v, field.min), field.max)
+
+	switch {
+	case field.value.CanInt():
+		field.value.SetInt(int64(math.Round(v)))
+	case field.value.CanUint():
+		field.value.SetUint(uint64(math.Round(v)))
+	default:
+		field.value.SetFloat(v)
+	}
+}
+
+type formTag struct {
+	skip   bool
+	values map[string]string
+}
+
+// parseFormTag parses `form:"key=value,key=value"`.
+func parseFormTag(tag string) formTag {
+	result := formTag{values: map[string]string{}}
+	if tag == "-" {
+		result.skip = true
+		return result
+	}
+	for _, part := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if key != "" {
+			result.values[key] = value
+		}
+	}
+	return result
+}
@@ -1,43 +1,77 @@
 package generator
 
 type Config struct {
-	Function Function
-	Scale    Scale
+	// Functions contains the source for each input channel.
+	Functions [Inputs]Function `form:"labels=A|B"`
+	Scale     Scale
 }
 
 var DefaultConfig = Config{
-	Function: Sin,
-	Scale:    Small,
+	Functions: [Inputs]Function{Sin, Triangle},
+	Scale:     Small,
 }
 
-type Function byte
+// Channel identifies a signal in Data.
+type Channel byte
 
 const (
-	Sin = Function(iota)
-	Sawtooth
-	SinSaw
-	Random
+	// ChannelA and ChannelB are generated from the sources.
+	ChannelA = Channel(iota)
+	ChannelB
+
+	// Math channels are calculated from the input channels.
+	ChannelSum
+	ChannelDifference
+	ChannelProduct
+
+	// Channels is the number of channels in Data.
+	Channels = int(iota)
+	// Inputs is the number of channels with a source.
+	Inputs = int(ChannelB) + 1
 )
 
-func (s Function) String() string {
-	switch s {
-	case Sin:
-		return "SIN"
-	case Sawtooth:
-		return "SAW"
-	case SinSaw:
-		return "SNW"
-	case Random:
-		return "RNG"
+func (c Channel) String() string {
+	switch c {
+	case ChannelA:
+		return "A"
+	case ChannelB:
+		return "B"
+	case ChannelSum:
+		return "A+B"
+	case ChannelDifference:
+		return "A−B"
+	case ChannelProduct:
+		return "A×B"
 	default:
 		return "???"
 	}
 }
 
-func (Function) Options() []Function {
-	return []Function{Sin, Sawtooth, SinSaw, Random}
+func (Channel) Options() []Channel {
+	return []Channel{ChannelA, ChannelB, ChannelSum, ChannelDifference, ChannelProduct}
 }
 
+// Function is the name of a registered Source.
+type Function string
+
+const (
+	Sin      = Function("SIN")
+	Sawtooth = Function("SAW")
+	SinSaw   = Function("SNW")
+	Random   = Function("RNG")
+
+	Square     = Function("SQR")
+	Triangle   = Function("TRI")
+	WhiteNoise = Function("WHT")
+	PinkNoise  = Function("PNK")
+	BrownNoise = Function("BRN")
+)
+
+func (s Function) String() string { return string(s) }
+
+// Options returns all the registered functions.
+func (Function) Options() []Function { return registered() }
+
 type Scale byte
 
 const (
@@ -0,0 +1,190 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type Control string
+
+const (
+	Ping  = Control("Ping")
+	Tune  = Control("Tune")
+	Trace = Control("Trace")
+)
+
+// ProgressState is the state of a running control.
+type ProgressState byte
+
+const (
+	Running = ProgressState(iota)
+	Completed
+	Cancelled
+	Failed
+	// Rejected means the control conflicted with an already running one.
+	Rejected
+)
+
+func (state ProgressState) String() string {
+	switch state {
+	case Running:
+		return "running"
+	case Completed:
+		return "completed"
+	case Cancelled:
+		return "cancelled"
+	case Failed:
+		return "failed"
+	case Rejected:
+		return "rejected"
+	default:
+		return "???"
+	}
+}
+
+// Progress reports the state of a control.
+type Progress struct {
+	Control Control
+	State   ProgressState
+	Done    float32 // fraction in range 0..1
+	Result  string
+}
+
+// Final reports whether the control has finished.
+func (progress Progress) Final() bool { return progress.State != Running }
+
+func (progress Progress) String() string {
+	if progress.Result == "" {
+		return string(progress.Control) + " " + progress.State.String()
+	}
+	return string(progress.Control) + " " + progress.State.String() + ": " + progress.Result
+}
+
+// command is a request to start or to cancel a control.
+type command struct {
+	control Control
+	cancel  bool
+}
+
+// commandQueue is an unbounded queue of commands, so that none of the
+// commands issued by the user is lost while Run is busy.
+type commandQueue struct {
+	mu      sync.Mutex
+	pending []command
+	// ready is signalled when pending is not empty.
+	ready chan struct{}
+}
+
+func (queue *commandQueue) push(cmd command) {
+	queue.mu.Lock()
+	queue.pending = append(queue.pending, cmd)
+	queue.mu.Unlock()
+
+	select {
+	case queue.ready <- struct{}{}:
+	default:
+	}
+}
+
+// take removes all the pending commands.
+func (queue *commandQueue) take() []command {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	cmds := queue.pending
+	queue.pending = nil
+	return cmds
+}
+
+// conflicts reports whether a and b must not run at the same time.
+func conflicts(a, b Control) bool {
+	if a == b {
+		return true
+	}
+	// tuning and tracing both sweep the device
+	return (a == Tune && b == Trace) || (a == Trace && b == Tune)
+}
+
+// start runs the control concurrently, unless it conflicts with
+// one of the running controls.
+func (client *Client) start(ctx context.Context, control Control) {
+	for other := range client.running {
+		if conflicts(control, other) {
+			client.report(ctx, Progress{
+				Control: control,
+				State:   Rejected,
+				Result:  string(other) + " is running",
+			})
+			return
+		}
+	}
+
+	runctx, cancel := context.WithCancel(ctx)
+	client.running[control] = cancel
+
+	go func() {
+		defer cancel()
+
+		client.report(runctx, Progress{Control: control, State: Running})
+		result, err := simulate(runctx, control, func(done float32) {
+			client.report(runctx, Progress{Control: control, State: Running, Done: done})
+		})
+
+		final := Progress{Control: control, State: Completed, Done: 1, Result: result}
+		if err != nil {
+			final = Progress{Control: control, State: Failed, Result: err.Error()}
+			if runctx.Err() != nil {
+				final = Progress{Control: control, State: Cancelled}
+			}
+		}
+		client.report(ctx, final)
+
+		select {
+		case client.finished <- control:
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// report sends the progress, unless ctx is cancelled.
+func (client *Client) report(ctx context.Context, progress Progress) {
+	select {
+	case client.Progress <- progress:
+	case <-ctx.Done():
+	}
+}
+
+// simulate pretends to run the control on a device.
+func simulate(ctx context.Context, control Control, progress func(done float32)) (string, error) {
+	steps, delay := 3, 100*time.Millisecond
+	switch control {
+	case Tune:
+		steps = 20
+	case Trace:
+		steps, delay = 10, 150*time.Millisecond
+	}
+
+	tick := time.NewTicker(delay)
+	defer tick.Stop()
+	for step := 1; step <= steps; step++ {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-tick.C:
+		}
+		progress(float32(step) / float32(steps))
+	}
+
+	switch control {
+	case Ping:
+		return fmt.Sprintf("round trip %dms", 5+rand.Intn(20)), nil
+	case Tune:
+		return fmt.Sprintf("offset %+.1fmV", rand.Float64()*10-5), nil
+	case Trace:
+		return fmt.Sprintf("%d points traced", 500+rand.Intn(500)), nil
+	default:
+		return "", fmt.Errorf("unknown control %q", control)
+	}
+}
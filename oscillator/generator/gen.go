@@ -2,58 +2,122 @@ package generator
 
 import (
 	"context"
-	"math"
-	"math/rand"
 	"time"
 )
 
 const (
 	DataFrequency  = 30 * time.Millisecond
 	UpdateDebounce = 500 * time.Millisecond
+
+	// SampleRate is the number of samples per second.
+	SampleRate = 1000
+	// Epoch is how often the time of the samples restarts from zero,
+	// otherwise float32 can't represent the sample interval after a few
+	// hours. The sources still see a continuous time.
+	Epoch = 10 * time.Minute
+
+	// Window is the duration of the signal in each Data, it must be
+	// longer than the span of the slowest timebase of the scope (10 × 0.1s),
+	// otherwise the trigger never finds a full trace.
+	Window = 2 * time.Second
 )
 
 type Client struct {
-	Status chan Status
-	Data   chan Data
+	Status   chan Status
+	Data     chan Data
+	Progress chan Progress
 
-	reconf  chan Config
-	control chan Control
-	active  Config
+	reconf   chan Config
+	commands commandQueue
+	active   Config
 
-	initial Config
-}
+	// running contains the cancel funcs of the running controls.
+	running  map[Control]context.CancelFunc
+	finished chan Control
 
-type Control string
+	sources [Inputs]Source
+	// clock is the index of the next sample.
+	clock int64
+	// epoch is the index of the sample at time zero in Data.
+	epoch  int64
+	window sampleWindow
 
-const (
-	Ping  = Control("Ping")
-	Tune  = Control("Tune")
-	Trace = Control("Trace")
-)
+	// fresh, when set, receives the generated samples instead of Data.
+	fresh chan Samples
+
+	initial Config
+
+	// Recorder, when set, records the samples of all the sent Data.
+	Recorder *Recorder
+	// replay contains the frames to send instead of generating them.
+	replay []Frame
+	// addr is the server to connect to instead of generating the data.
+	addr string
+}
 
 type Status string
 
 type Data struct {
-	Status   string
+	Status string
+	// Channels is indexed by Channel.
+	Channels [Channels]Signal
+}
+
+// Signal is the data of a single channel.
+type Signal struct {
 	Values   []Point
 	Min, Max Point
 }
 
+// updateBounds calculates Min and Max from Values.
+func (signal *Signal) updateBounds() {
+	if len(signal.Values) == 0 {
+		return
+	}
+	signal.Min = signal.Values[0]
+	signal.Max = signal.Values[0]
+	for _, v := range signal.Values {
+		signal.Min = signal.Min.Min(v)
+		signal.Max = signal.Max.Max(v)
+	}
+}
+
 func NewClient(initial Config) *Client {
 	return &Client{
-		Status: make(chan Status, 1),
-		Data:   make(chan Data, 1),
+		Status:   make(chan Status, 1),
+		Data:     make(chan Data, 1),
+		Progress: make(chan Progress, 16),
 
-		reconf:  make(chan Config, 1),
-		control: make(chan Control, 1),
+		reconf:   make(chan Config, 1),
+		commands: commandQueue{ready: make(chan struct{}, 1)},
+
+		running:  map[Control]context.CancelFunc{},
+		finished: make(chan Control),
 
 		initial: initial,
 	}
 }
 
+// NewReplayClient creates a client that sends the recorded frames
+// in a loop, instead of generating them.
+func NewReplayClient(frames []Frame) *Client {
+	client := NewClient(frames[0].Config)
+	client.replay = frames
+	return client
+}
+
 func (client *Client) InitialConfig() Config { return client.initial }
 
 func (client *Client) Run(ctx context.Context) {
+	if client.replay != nil {
+		client.runReplay(ctx)
+		return
+	}
+	if client.addr != "" {
+		client.runRemote(ctx)
+		return
+	}
+
 	client.reconfigure(client.initial)
 
 	tick := time.NewTicker(DataFrequency)
@@ -65,68 +129,149 @@ func (client *Client) Run(ctx context.Context) {
 			return
 
 		case <-tick.C:
-			const N = 100
-
-			data := Data{
-				Status: "OK",
-				Values: make([]Point, N),
+			samples := client.advance(int(SampleRate * DataFrequency / time.Second))
+			if client.fresh != nil {
+				// the samples must not be dropped, otherwise the
+				// receiver can't rebuild the window
+				select {
+				case client.fresh <- samples:
+				case <-ctx.Done():
+					return
+				}
+				continue
 			}
 
-			for i := range data.Values {
-				var p Point
-
-				now := float64(time.Now().UnixMilli()%10000) / 1000
-				t := float64(i)/N + now
-				switch client.active.Function {
-				case Sin:
-					p = Point{X: float32(t), Y: float32(math.Sin(t))}
-				case Sawtooth:
-					p = Point{X: float32(t), Y: float32(math.Mod(t, 1))}
-				case SinSaw:
-					p = Point{X: float32(t), Y: float32(math.Sin(t*2.89) * math.Mod(t, 1))}
-				case Random:
-					p = Point{X: rand.Float32(), Y: rand.Float32()}
-				}
+			client.send(Frame{
+				Time:    time.Now(),
+				Config:  client.active,
+				Samples: samples,
+			})
 
-				switch client.active.Scale {
-				case Small:
-					p.X *= 0.1
-					p.Y *= 0.1
-				case Medium:
-				case Large:
-					p.X *= p.Y
+		case next := <-client.reconf:
+			sendAndDropOld(client.Status, "Waiting for more updates")
+			next = debounce(client.reconf, next, UpdateDebounce)
+
+			// finally update
+			client.reconfigure(next)
+
+		case <-client.commands.ready:
+			for _, cmd := range client.commands.take() {
+				if !cmd.cancel {
+					client.start(ctx, cmd.control)
+				} else if cancel, ok := client.running[cmd.control]; ok {
+					cancel()
 				}
+			}
 
-				data.Values[i] = p
+		case ctrl := <-client.finished:
+			delete(client.running, ctrl)
+		}
+	}
+}
+
+// runReplay sends the recorded frames with the original timing.
+func (client *Client) runReplay(ctx context.Context) {
+	client.active = client.initial
+	sendAndDropOld(client.Status, "Replaying")
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	next := 0
+	for ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-timer.C:
+			frame := client.replay[next]
+			client.active = frame.Config
+			client.send(frame)
+
+			next = (next + 1) % len(client.replay)
+			delay := DataFrequency
+			if next > 0 {
+				delay = client.replay[next].Time.Sub(frame.Time)
+				delay = min(max(delay, 0), time.Second)
 			}
+			timer.Reset(delay)
+
+		case <-client.reconf:
+			sendAndDropOld(client.Status, "Replaying, configuration is disabled")
 
-			data.Min = data.Values[0]
-			data.Max = data.Values[0]
-			for _, v := range data.Values {
-				data.Min = data.Min.Min(v)
-				data.Max = data.Max.Max(v)
+		case <-client.commands.ready:
+			for _, cmd := range client.commands.take() {
+				if !cmd.cancel {
+					client.report(ctx, Progress{Control: cmd.control, State: Rejected, Result: "replaying"})
+				}
 			}
+		}
+	}
+}
 
-			sendAndDropOld(client.Data, data)
+// send adds the samples to the window, records the frame and sends
+// the data, dropping the previous data when it hasn't been received yet.
+func (client *Client) send(frame Frame) {
+	client.window.add(frame.Samples)
+	if client.Recorder != nil {
+		if err := client.Recorder.Record(frame); err != nil {
+			sendAndDropOld(client.Status, Status("Recording failed: "+err.Error()))
+		}
+	}
+	sendAndDropOld(client.Data, client.window.data())
+}
 
-		case next := <-client.reconf:
-			sendAndDropOld(client.Status, "Waiting for more updates")
-			next = debounce(client.reconf, next, UpdateDebounce)
+// advance generates n new samples, on start it generates the whole
+// Window, so that the display has something to show.
+func (client *Client) advance(n int) Samples {
+	n = max(n, windowSamples-int(client.clock))
 
-			// finally update
-			client.reconfigure(next)
+	end := client.clock + int64(n)
+	if end-int64(windowSamples)-client.epoch >= epochSamples {
+		client.epoch = end - int64(windowSamples)
+	}
+
+	gain := float32(1)
+	switch client.active.Scale {
+	case Small:
+		gain = 0.1
+	case Medium:
+	case Large:
+		gain = 10
+	}
+
+	samples := Samples{Clock: client.clock, Epoch: client.epoch}
+	fresh := make([]float64, n)
+	for input, source := range client.sources {
+		source.Sample(float64(client.clock)/SampleRate, 1.0/SampleRate, fresh)
 
-		case ctrl := <-client.control:
-			sendAndDropOld(client.Status, Status(string(ctrl)+" started"))
-			time.Sleep(300 * time.Millisecond)
-			sendAndDropOld(client.Status, Status(string(ctrl)+" complete"))
+		values := make([]float32, n)
+		for i, v := range fresh {
+			values[i] = float32(v) * gain
 		}
+		samples.Inputs[input] = values
 	}
+	client.clock = end
+
+	return samples
 }
 
 func (client *Client) reconfigure(next Config) {
 	// TODO: this could also check what has changed between p.active and next
 	// to only update the necessary things
+	for input, name := range next.Functions {
+		source, ok := NewSource(name)
+		if !ok {
+			sendAndDropOld(client.Status, Status("Unknown function "+name.String()))
+			next.Functions[input] = client.active.Functions[input]
+			source = client.sources[input]
+			if source == nil {
+				next.Functions[input] = DefaultConfig.Functions[input]
+				source, _ = NewSource(next.Functions[input])
+			}
+		}
+		client.sources[input] = source
+	}
 	client.active = next
 
 	sendAndDropOld(client.Status, "Reconfiguring")
@@ -141,8 +286,14 @@ func (client *Client) Reconf(config Config) {
 	sendAndDropOld(client.reconf, config)
 }
 
+// Control starts the control, the progress is reported in Progress.
 func (client *Client) Control(control Control) {
-	sendAndDropOld(client.control, control)
+	client.commands.push(command{control: control})
+}
+
+// Cancel stops the control, when it's running.
+func (client *Client) Cancel(control Control) {
+	client.commands.push(command{control: control, cancel: true})
 }
 
 type Point struct {
@@ -0,0 +1,132 @@
+package generator
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// Frame is a single recorded Data. It contains only the new samples,
+// the Data is rebuilt from the preceding frames, see DataAt.
+type Frame struct {
+	Time    time.Time
+	Config  Config
+	Samples Samples
+}
+
+// DataAt rebuilds the Data at frames[i] from the samples of the frames
+// up to i.
+func DataAt(frames []Frame, i int) Data {
+	first := i
+	for n := frames[i].Samples.Len(); first > 0 && n < windowSamples; {
+		first--
+		n += frames[first].Samples.Len()
+	}
+
+	var window sampleWindow
+	for _, frame := range frames[first : i+1] {
+		window.add(frame.Samples)
+	}
+	return window.data()
+}
+
+// Recorder keeps the latest frames in a ring buffer and optionally
+// writes all of them to a file.
+type Recorder struct {
+	mu     sync.Mutex
+	frames []Frame
+	next   int
+	full   bool
+
+	// write guards the fields below, so that writing to the file
+	// doesn't block Frames.
+	write sync.Mutex
+	out   *bufio.Writer
+	enc   *gob.Encoder
+	err   error
+}
+
+// NewRecorder creates a recorder that keeps the last capacity frames.
+// When w is not nil, all frames are also written to w.
+func NewRecorder(capacity int, w io.Writer) *Recorder {
+	recorder := &Recorder{
+		frames: make([]Frame, max(capacity, 1)),
+	}
+	if w != nil {
+		recorder.out = bufio.NewWriter(w)
+		recorder.enc = gob.NewEncoder(recorder.out)
+	}
+	return recorder
+}
+
+// Record adds a frame to the recording. It returns the error when
+// writing the frame fails, after that the frames are only kept in memory.
+func (recorder *Recorder) Record(frame Frame) error {
+	recorder.mu.Lock()
+	recorder.frames[recorder.next] = frame
+	recorder.next++
+	if recorder.next >= len(recorder.frames) {
+		recorder.next = 0
+		recorder.full = true
+	}
+	recorder.mu.Unlock()
+
+	recorder.write.Lock()
+	defer recorder.write.Unlock()
+
+	if recorder.enc == nil || recorder.err != nil {
+		return nil
+	}
+	recorder.err = recorder.enc.Encode(frame)
+	return recorder.err
+}
+
+// Frames returns the frames in the ring buffer, oldest first.
+func (recorder *Recorder) Frames() []Frame {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if !recorder.full {
+		return append([]Frame(nil), recorder.frames[:recorder.next]...)
+	}
+	frames := make([]Frame, 0, len(recorder.frames))
+	frames = append(frames, recorder.frames[recorder.next:]...)
+	frames = append(frames, recorder.frames[:recorder.next]...)
+	return frames
+}
+
+// Close flushes the file and returns the first write error.
+func (recorder *Recorder) Close() error {
+	recorder.write.Lock()
+	defer recorder.write.Unlock()
+
+	if recorder.out != nil && recorder.err == nil {
+		recorder.err = recorder.out.Flush()
+	}
+	recorder.enc = nil
+	return recorder.err
+}
+
+// ReadRecording reads all frames written by a Recorder.
+func ReadRecording(r io.Reader) ([]Frame, error) {
+	dec := gob.NewDecoder(bufio.NewReader(r))
+
+	var frames []Frame
+	for {
+		var frame Frame
+		if err := dec.Decode(&frame); err != nil {
+			// a recording that was cut short is still usable
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				if len(frames) == 0 {
+					return nil, errors.New("empty recording")
+				}
+				return frames, nil
+			}
+			return frames, err
+		}
+		frames = append(frames, frame)
+	}
+}
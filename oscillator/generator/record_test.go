@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecording(t *testing.T) {
+	var frames []Frame
+	var window sampleWindow
+	var live []Data
+	for k := range 100 {
+		// the first frame fills the window, as the generator does
+		n, clock := 30, int64(windowSamples+(k-1)*30)
+		if k == 0 {
+			n, clock = windowSamples, 0
+		}
+		samples := Samples{Clock: clock}
+		for input := range samples.Inputs {
+			samples.Inputs[input] = make([]float32, n)
+			for i := range n {
+				samples.Inputs[input][i] = float32(clock+int64(i)) * float32(input+1)
+			}
+		}
+
+		frame := Frame{Time: time.Unix(int64(k), 0), Config: DefaultConfig, Samples: samples}
+		frames = append(frames, frame)
+		window.add(samples)
+		live = append(live, window.data())
+	}
+
+	var file bytes.Buffer
+	recorder := NewRecorder(10, &file)
+	for _, frame := range frames {
+		if err := recorder.Record(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	recorded, err := ReadRecording(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != len(frames) {
+		t.Fatalf("got %d frames, want %d", len(recorded), len(frames))
+	}
+	for _, i := range []int{0, 1, 50, 99} {
+		if !reflect.DeepEqual(DataAt(recorded, i), live[i]) {
+			t.Errorf("frame %d: data differs from the live data", i)
+		}
+	}
+
+	history := recorder.Frames()
+	if len(history) != 10 || !history[0].Time.Equal(frames[90].Time) {
+		t.Fatalf("got %d frames in history", len(history))
+	}
+	// the history contains only the last 10 frames of samples
+	got := DataAt(history, 9).Channels[ChannelA].Values
+	want := live[99].Channels[ChannelA].Values
+	if want = want[len(want)-10*30:]; !slices.Equal(got, want) {
+		t.Errorf("history: got %d values, differs from the live data", len(got))
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestRecorderWriteError(t *testing.T) {
+	recorder := NewRecorder(10, failingWriter{})
+
+	// the frames are buffered, so the error shows up once the buffer is full
+	var failed int
+	for k := range 100 {
+		samples := Samples{Clock: int64(k * 30)}
+		for input := range samples.Inputs {
+			samples.Inputs[input] = make([]float32, 30)
+		}
+		if err := recorder.Record(Frame{Samples: samples}); err != nil {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Errorf("got %d errors, want 1", failed)
+	}
+	if err := recorder.Close(); err == nil {
+		t.Errorf("expected an error on close")
+	}
+	if got := len(recorder.Frames()); got != 10 {
+		t.Errorf("got %d frames in history, want 10", got)
+	}
+}
@@ -0,0 +1,159 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	// MinBackoff and MaxBackoff limit the delay between reconnects.
+	MinBackoff = 250 * time.Millisecond
+	MaxBackoff = 10 * time.Second
+)
+
+// NewRemoteClient creates a client that receives the data from a
+// server at addr, see Serve.
+func NewRemoteClient(addr string, initial Config) *Client {
+	client := NewClient(initial)
+	client.addr = addr
+	return client
+}
+
+// runRemote keeps a connection to the server, reconnecting with an
+// exponential backoff when it fails.
+func (client *Client) runRemote(ctx context.Context) {
+	client.active = client.initial
+
+	backoff := MinBackoff
+	for ctx.Err() == nil {
+		sendAndDropOld(client.Status, Status("Connecting to "+client.addr))
+
+		connected, err := client.connect(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = MinBackoff
+		}
+
+		sendAndDropOld(client.Status, Status(fmt.Sprintf("Disconnected (%v), retrying in %v", err, backoff)))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, MaxBackoff)
+	}
+}
+
+// connect handles a single connection until it fails.
+func (client *Client) connect(ctx context.Context) (connected bool, err error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network(client.addr), client.addr)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	wire := newWire(conn)
+	if err := wire.sendConfig(client.active); err != nil {
+		return false, err
+	}
+	sendAndDropOld(client.Status, Status("Connected to "+client.addr))
+
+	// the controls are lost together with the connection
+	defer func() {
+		for ctrl := range client.running {
+			client.report(ctx, Progress{Control: ctrl, State: Failed, Result: "connection lost"})
+			delete(client.running, ctrl)
+		}
+	}()
+
+	// messages are handled here, so that client.active is only
+	// accessed from a single goroutine
+	incoming := make(chan message)
+	failed := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			msg, err := wire.receive()
+			if err != nil {
+				failed <- err
+				return
+			}
+			select {
+			case incoming <- msg:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+
+		case err := <-failed:
+			return true, err
+
+		case msg := <-incoming:
+			switch msg.kind {
+			case messageStatus:
+				sendAndDropOld(client.Status, Status(msg.payload))
+			case messageSamples:
+				samples, err := decodeSamples(msg.payload)
+				if err != nil {
+					return true, err
+				}
+				client.send(Frame{
+					Time:    time.Now(),
+					Config:  client.active,
+					Samples: samples,
+				})
+			case messageProgress:
+				progress, err := decodeProgress(msg.payload)
+				if err != nil {
+					return true, err
+				}
+				if progress.Final() {
+					delete(client.running, progress.Control)
+				} else {
+					// remote controls are cancelled through the server
+					client.running[progress.Control] = nil
+				}
+				client.report(ctx, progress)
+			default:
+				return true, errors.New("unexpected message from server")
+			}
+
+		case next := <-client.reconf:
+			client.active = next
+			if err := wire.sendConfig(next); err != nil {
+				return true, err
+			}
+
+		case <-client.commands.ready:
+			cmds := client.commands.take()
+			for i, cmd := range cmds {
+				kind := messageControl
+				if cmd.cancel {
+					kind = messageCancel
+				}
+				if err := wire.send(kind, []byte(cmd.control)); err != nil {
+					// the user needs to know that the rest didn't start either
+					for _, unsent := range cmds[i:] {
+						if !unsent.cancel {
+							client.report(ctx, Progress{Control: unsent.control, State: Failed, Result: "connection lost"})
+						}
+					}
+					return true, err
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package generator
+
+import "time"
+
+const (
+	// windowSamples is the number of samples in Window.
+	windowSamples = int(SampleRate * Window / time.Second)
+	// epochSamples is the number of samples in Epoch.
+	epochSamples = int64(SampleRate * Epoch / time.Second)
+)
+
+// Samples are the input samples generated since the previous Data.
+type Samples struct {
+	// Clock is the index of the first sample since the device started.
+	Clock int64
+	// Epoch is the index of the sample at time zero.
+	Epoch int64
+	// Inputs contains the scaled values of each input, all of them
+	// have the same length.
+	Inputs [Inputs][]float32
+}
+
+// Len returns the number of samples per input.
+func (samples Samples) Len() int { return len(samples.Inputs[0]) }
+
+// sampleWindow keeps the last Window of the input samples,
+// so that the math channels can be calculated from them.
+type sampleWindow struct {
+	// clock is the index of the first sample in inputs.
+	clock  int64
+	epoch  int64
+	inputs [Inputs][]float32
+}
+
+// add appends the samples to the window, the window starts over when
+// the samples don't continue the previous ones, e.g. after a reconnect.
+func (window *sampleWindow) add(samples Samples) {
+	n := len(window.inputs[0])
+	if samples.Clock != window.clock+int64(n) {
+		*window = sampleWindow{clock: samples.Clock}
+	}
+	window.epoch = samples.Epoch
+
+	for input, fresh := range samples.Inputs {
+		window.inputs[input] = append(window.inputs[input], fresh...)
+	}
+	if extra := len(window.inputs[0]) - windowSamples; extra > 0 {
+		for input, values := range window.inputs {
+			window.inputs[input] = append(values[:0], values[extra:]...)
+		}
+		window.clock += int64(extra)
+	}
+}
+
+// data converts the window to Data and calculates the math channels.
+func (window *sampleWindow) data() Data {
+	data := Data{Status: "OK"}
+
+	n := len(window.inputs[0])
+	for c := range data.Channels {
+		data.Channels[c].Values = make([]Point, n)
+	}
+	for i := range n {
+		x := float32(float64(window.clock-window.epoch+int64(i)) / SampleRate)
+
+		a, b := window.inputs[ChannelA][i], window.inputs[ChannelB][i]
+		data.Channels[ChannelA].Values[i] = Point{X: x, Y: a}
+		data.Channels[ChannelB].Values[i] = Point{X: x, Y: b}
+		data.Channels[ChannelSum].Values[i] = Point{X: x, Y: a + b}
+		data.Channels[ChannelDifference].Values[i] = Point{X: x, Y: a - b}
+		data.Channels[ChannelProduct].Values[i] = Point{X: x, Y: a * b}
+	}
+
+	for c := range data.Channels {
+		data.Channels[c].updateBounds()
+	}
+
+	return data
+}
@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSampleWindow(t *testing.T) {
+	fresh := func(clock, epoch int64, a, b []float32) Samples {
+		return Samples{Clock: clock, Epoch: epoch, Inputs: [Inputs][]float32{a, b}}
+	}
+
+	var window sampleWindow
+	window.add(fresh(10, 10, []float32{1, 2}, []float32{3, 4}))
+	window.add(fresh(12, 10, []float32{-1}, []float32{2}))
+
+	data := window.data()
+	want := [Channels][]Point{
+		ChannelA:          {{0, 1}, {0.001, 2}, {0.002, -1}},
+		ChannelB:          {{0, 3}, {0.001, 4}, {0.002, 2}},
+		ChannelSum:        {{0, 4}, {0.001, 6}, {0.002, 1}},
+		ChannelDifference: {{0, -2}, {0.001, -2}, {0.002, -3}},
+		ChannelProduct:    {{0, 3}, {0.001, 8}, {0.002, -2}},
+	}
+	for c, values := range want {
+		if got := data.Channels[c].Values; !slices.Equal(got, values) {
+			t.Errorf("%v: got %v, want %v", Channel(c), got, values)
+		}
+	}
+	if got := data.Channels[ChannelSum].Max; got != (Point{0.002, 6}) {
+		t.Errorf("sum max: got %v", got)
+	}
+
+	// the samples don't continue the window, e.g. after a reconnect
+	window.add(fresh(0, 0, []float32{5}, []float32{6}))
+	if got := window.data().Channels[ChannelA].Values; !slices.Equal(got, []Point{{0, 5}}) {
+		t.Errorf("restart: got %v", got)
+	}
+
+	// only the last Window is kept, continuing from the previous sample
+	long := make([]float32, windowSamples+5)
+	for i := range long {
+		long[i] = float32(i)
+	}
+	window.add(fresh(1, 0, long, long))
+	values := window.data().Channels[ChannelA].Values
+	if len(values) != windowSamples {
+		t.Fatalf("got %d values, want %d", len(values), windowSamples)
+	}
+	if first := values[0]; first.Y != 5 || first.X != 0.006 {
+		t.Errorf("first: got %v", first)
+	}
+}
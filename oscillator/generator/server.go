@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+)
+
+// Serve accepts connections from remote clients and runs a simulated
+// device for each of them, until ctx is cancelled.
+func Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			if err := serveConn(ctx, conn); err != nil {
+				fmt.Fprintf(os.Stderr, "%v: %v\n", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// serveConn forwards the messages between the connection and a device.
+func serveConn(ctx context.Context, conn net.Conn) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer func() { _ = conn.Close() }()
+
+	wire := newWire(conn)
+
+	first, err := wire.receive()
+	if err != nil {
+		return err
+	}
+	if first.kind != messageConfig {
+		return errors.New("expected config as the first message")
+	}
+	initial, err := decodeConfig(first.payload)
+	if err != nil {
+		return err
+	}
+
+	device := NewClient(initial)
+	device.fresh = make(chan Samples, 1)
+	go device.Run(ctx)
+
+	// sending fails when the connection is closed, which also
+	// ends the receiving below
+	go func() {
+		defer cancel()
+		for {
+			var err error
+			select {
+			case <-ctx.Done():
+				return
+			case status := <-device.Status:
+				err = wire.send(messageStatus, []byte(status))
+			case samples := <-device.fresh:
+				err = wire.send(messageSamples, encodeSamples(samples))
+			case progress := <-device.Progress:
+				err = wire.sendProgress(progress)
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	for {
+		msg, err := wire.receive()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		switch msg.kind {
+		case messageConfig:
+			config, err := decodeConfig(msg.payload)
+			if err != nil {
+				return err
+			}
+			device.Reconf(config)
+		case messageControl:
+			device.Control(Control(msg.payload))
+		case messageCancel:
+			device.Cancel(Control(msg.payload))
+		default:
+			return fmt.Errorf("unexpected message %d", msg.kind)
+		}
+	}
+}
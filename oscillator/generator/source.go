@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// Source generates a signal.
+type Source interface {
+	// Sample fills values with consecutive samples, where the first sample
+	// is at time t seconds and the following ones are dt seconds apart.
+	Sample(t, dt float64, values []float64)
+}
+
+// SourceFunc adapts a function of time to a Source.
+type SourceFunc func(t float64) float64
+
+func (fn SourceFunc) Sample(t, dt float64, values []float64) {
+	for i := range values {
+		values[i] = fn(t + float64(i)*dt)
+	}
+}
+
+var sources struct {
+	sync.Mutex
+	names  []Function
+	create map[Function]func() Source
+}
+
+// Register makes the source available as a Function.
+//
+// create is called on each reconfiguration, hence the sources may keep
+// state between calls to Sample. Registering the same name twice panics.
+func Register(name Function, create func() Source) {
+	sources.Lock()
+	defer sources.Unlock()
+
+	if sources.create == nil {
+		sources.create = map[Function]func() Source{}
+	}
+	if _, exists := sources.create[name]; exists {
+		panic(fmt.Sprintf("generator: source %q registered twice", name))
+	}
+	sources.names = append(sources.names, name)
+	sources.create[name] = create
+}
+
+// NewSource creates the source registered for the function.
+func NewSource(name Function) (Source, bool) {
+	sources.Lock()
+	defer sources.Unlock()
+
+	create, ok := sources.create[name]
+	if !ok {
+		return nil, false
+	}
+	return create(), true
+}
+
+// registered returns the registered functions in the order of registration.
+func registered() []Function {
+	sources.Lock()
+	defer sources.Unlock()
+	return append([]Function(nil), sources.names...)
+}
+
+func init() {
+	stateless := func(fn SourceFunc) func() Source {
+		return func() Source { return fn }
+	}
+
+	Register(Sin, stateless(math.Sin))
+	Register(Sawtooth, stateless(func(t float64) float64 { return math.Mod(t, 1) }))
+	Register(SinSaw, stateless(func(t float64) float64 { return math.Sin(t*2.89) * math.Mod(t, 1) }))
+	Register(Random, stateless(func(t float64) float64 { return rand.Float64() }))
+
+	Register(Square, stateless(func(t float64) float64 {
+		if math.Mod(t, 1) < 0.5 {
+			return 1
+		}
+		return -1
+	}))
+	Register(Triangle, stateless(func(t float64) float64 {
+		return 1 - 4*math.Abs(math.Mod(t, 1)-0.5)
+	}))
+
+	Register(WhiteNoise, stateless(func(t float64) float64 { return rand.Float64()*2 - 1 }))
+	Register(PinkNoise, func() Source { return &pinkNoise{} })
+	Register(BrownNoise, func() Source { return &brownNoise{} })
+}
+
+// pinkNoise filters white noise to have -3dB/octave falloff, using
+// Paul Kellet's economy filter.
+type pinkNoise struct {
+	b0, b1, b2 float64
+}
+
+func (noise *pinkNoise) Sample(t, dt float64, values []float64) {
+	for i := range values {
+		white := rand.Float64()*2 - 1
+		noise.b0 = 0.99765*noise.b0 + white*0.0990460
+		noise.b1 = 0.96300*noise.b1 + white*0.2965164
+		noise.b2 = 0.57000*noise.b2 + white*1.0526913
+		values[i] = (noise.b0 + noise.b1 + noise.b2 + white*0.1848) * 0.25
+	}
+}
+
+// brownNoise integrates white noise to have -6dB/octave falloff.
+type brownNoise struct {
+	last float64
+}
+
+func (noise *brownNoise) Sample(t, dt float64, values []float64) {
+	for i := range values {
+		white := rand.Float64()*2 - 1
+		noise.last = (noise.last + 0.02*white) / 1.02
+		values[i] = noise.last * 3.5
+	}
+}
@@ -0,0 +1,151 @@
+package generator
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// WAVSource plays back a mono mix of a WAV file in a loop.
+type WAVSource struct {
+	SampleRate float64
+	Samples    []float64
+}
+
+// OpenWAV reads the WAV file at path, see ReadWAV.
+func OpenWAV(path string) (*WAVSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	return ReadWAV(bufio.NewReader(f))
+}
+
+// ReadWAV reads an uncompressed 8, 16, 24 or 32-bit PCM or
+// 32-bit float WAV file.
+func ReadWAV(r io.Reader) (*WAVSource, error) {
+	var header struct {
+		RIFF [4]byte
+		Size uint32
+		WAVE [4]byte
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if string(header.RIFF[:]) != "RIFF" || string(header.WAVE[:]) != "WAVE" {
+		return nil, errors.New("not a WAV file")
+	}
+
+	var format struct {
+		AudioFormat   uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+	}
+	haveFormat := false
+
+	for {
+		var chunk struct {
+			ID   [4]byte
+			Size uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("missing data chunk")
+			}
+			return nil, err
+		}
+
+		body := io.LimitReader(r, int64(chunk.Size)+int64(chunk.Size%2))
+		switch string(chunk.ID[:]) {
+		case "fmt ":
+			if err := binary.Read(body, binary.LittleEndian, &format); err != nil {
+				return nil, fmt.Errorf("invalid fmt chunk: %w", err)
+			}
+			haveFormat = true
+		case "data":
+			if !haveFormat {
+				return nil, errors.New("data chunk before fmt chunk")
+			}
+			// the size comes from the file, hence read it incrementally
+			// instead of allocating it all upfront
+			data, err := io.ReadAll(io.LimitReader(body, int64(chunk.Size)))
+			if err != nil {
+				return nil, err
+			}
+			if int64(len(data)) < int64(chunk.Size) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return decodeWAV(data, int(format.AudioFormat), int(format.Channels), int(format.BitsPerSample), float64(format.SampleRate))
+		}
+
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// decodeWAV converts interleaved samples to mono in range -1..1.
+func decodeWAV(data []byte, audioFormat, channels, bits int, sampleRate float64) (*WAVSource, error) {
+	const (
+		formatPCM   = 1
+		formatFloat = 3
+	)
+
+	var decode func(b []byte) float64
+	switch {
+	case audioFormat == formatPCM && bits == 8:
+		decode = func(b []byte) float64 { return (float64(b[0]) - 128) / 128 }
+	case audioFormat == formatPCM && bits == 16:
+		decode = func(b []byte) float64 { return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15) }
+	case audioFormat == formatPCM && bits == 24:
+		decode = func(b []byte) float64 {
+			v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+			return float64(v) / (1 << 23)
+		}
+	case audioFormat == formatPCM && bits == 32:
+		decode = func(b []byte) float64 { return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31) }
+	case audioFormat == formatFloat && bits == 32:
+		decode = func(b []byte) float64 { return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))) }
+	default:
+		return nil, fmt.Errorf("unsupported WAV format %d with %d bits", audioFormat, bits)
+	}
+	if channels <= 0 || sampleRate <= 0 {
+		return nil, errors.New("invalid WAV format")
+	}
+
+	size := bits / 8
+	frame := size * channels
+	wav := &WAVSource{
+		SampleRate: sampleRate,
+		Samples:    make([]float64, len(data)/frame),
+	}
+	for i := range wav.Samples {
+		sum := 0.0
+		for c := range channels {
+			offset := i*frame + c*size
+			sum += decode(data[offset : offset+size])
+		}
+		wav.Samples[i] = sum / float64(channels)
+	}
+	if len(wav.Samples) == 0 {
+		return nil, errors.New("empty WAV file")
+	}
+	return wav, nil
+}
+
+func (wav *WAVSource) Sample(t, dt float64, values []float64) {
+	n := int64(len(wav.Samples))
+	for i := range values {
+		at := int64(math.Floor((t + float64(i)*dt) * wav.SampleRate))
+		values[i] = wav.Samples[((at%n)+n)%n]
+	}
+}
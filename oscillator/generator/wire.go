@@ -0,0 +1,200 @@
+package generator
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"strings"
+	"sync"
+)
+
+// The wire protocol consists of framed messages in both directions:
+//
+//	kind    uint8
+//	length  uint32, big-endian
+//	payload [length]byte
+//
+// The client sends Config, Control and Cancel messages, the server
+// sends Status, Samples and Progress messages. The first message from
+// the client is always the Config.
+//
+// The server sends only the new input samples, the client keeps the
+// window of the samples and calculates the math channels.
+type messageKind byte
+
+const (
+	messageConfig   = messageKind(1) // JSON encoded Config
+	messageControl  = messageKind(2) // Control as text
+	messageStatus   = messageKind(3) // Status as text
+	messageSamples  = messageKind(4) // Samples, see encodeSamples
+	messageCancel   = messageKind(5) // Control as text
+	messageProgress = messageKind(6) // JSON encoded Progress
+)
+
+// maxMessageSize limits the payload to avoid huge allocations on corrupt input.
+const maxMessageSize = 16 << 20
+
+type message struct {
+	kind    messageKind
+	payload []byte
+}
+
+// wire sends and receives messages over a connection.
+type wire struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+func newWire(conn net.Conn) *wire {
+	return &wire{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+		w:    bufio.NewWriter(conn),
+	}
+}
+
+// send writes a single message, it's safe to call concurrently.
+func (wire *wire) send(kind messageKind, payload []byte) error {
+	wire.mu.Lock()
+	defer wire.mu.Unlock()
+
+	var header [5]byte
+	header[0] = byte(kind)
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+	if _, err := wire.w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := wire.w.Write(payload); err != nil {
+		return err
+	}
+	return wire.w.Flush()
+}
+
+// receive reads a single message.
+func (wire *wire) receive() (message, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(wire.r, header[:]); err != nil {
+		return message{}, err
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > maxMessageSize {
+		return message{}, fmt.Errorf("message too large: %d bytes", size)
+	}
+
+	msg := message{
+		kind:    messageKind(header[0]),
+		payload: make([]byte, size),
+	}
+	if _, err := io.ReadFull(wire.r, msg.payload); err != nil {
+		return message{}, err
+	}
+	return msg, nil
+}
+
+func (wire *wire) sendConfig(config Config) error {
+	payload, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return wire.send(messageConfig, payload)
+}
+
+func (wire *wire) sendProgress(progress Progress) error {
+	payload, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	return wire.send(messageProgress, payload)
+}
+
+func decodeProgress(payload []byte) (Progress, error) {
+	var progress Progress
+	err := json.Unmarshal(payload, &progress)
+	return progress, err
+}
+
+func decodeConfig(payload []byte) (Config, error) {
+	var config Config
+	err := json.Unmarshal(payload, &config)
+	return config, err
+}
+
+// encodeSamples encodes the samples as:
+//
+//	clock  int64
+//	epoch  int64
+//	inputs uint8
+//	count  uint32
+//	for each input:
+//	    values [count]float32
+//
+// All values are big-endian.
+func encodeSamples(samples Samples) []byte {
+	count := samples.Len()
+
+	b := make([]byte, 0, 8+8+1+4+len(samples.Inputs)*count*4)
+	b = binary.BigEndian.AppendUint64(b, uint64(samples.Clock))
+	b = binary.BigEndian.AppendUint64(b, uint64(samples.Epoch))
+	b = append(b, byte(len(samples.Inputs)))
+	b = binary.BigEndian.AppendUint32(b, uint32(count))
+	for _, values := range samples.Inputs {
+		for _, v := range values[:count] {
+			b = binary.BigEndian.AppendUint32(b, math.Float32bits(v))
+		}
+	}
+	return b
+}
+
+func decodeSamples(b []byte) (Samples, error) {
+	errShort := errors.New("samples message too short")
+
+	var samples Samples
+	if len(b) < 8+8+1+4 {
+		return samples, errShort
+	}
+	samples.Clock = int64(binary.BigEndian.Uint64(b[0:]))
+	samples.Epoch = int64(binary.BigEndian.Uint64(b[8:]))
+	inputs := int(b[16])
+	count := int(binary.BigEndian.Uint32(b[17:]))
+	b = b[21:]
+
+	if inputs != len(samples.Inputs) {
+		return samples, fmt.Errorf("expected %d inputs, got %d", len(samples.Inputs), inputs)
+	}
+	if len(b) < inputs*count*4 {
+		return samples, errShort
+	}
+
+	for input := range samples.Inputs {
+		values := make([]float32, count)
+		for i := range values {
+			values[i] = math.Float32frombits(binary.BigEndian.Uint32(b))
+			b = b[4:]
+		}
+		samples.Inputs[input] = values
+	}
+	return samples, nil
+}
+
+// network returns the network for the address,
+// addresses containing a path separator are unix sockets.
+func network(addr string) string {
+	if strings.ContainsRune(addr, os.PathSeparator) {
+		return "unix"
+	}
+	return "tcp"
+}
+
+// Listen listens on a TCP address or a unix socket.
+func Listen(addr string) (net.Listener, error) {
+	return net.Listen(network(addr), addr)
+}
@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestEncodeSamples(t *testing.T) {
+	samples := Samples{
+		Clock:  0x0102,
+		Epoch:  0x01,
+		Inputs: [Inputs][]float32{{1}, {-2}},
+	}
+
+	want := []byte{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x02, // clock
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, // epoch
+		0x02,                   // inputs
+		0x00, 0x00, 0x00, 0x01, // count
+		0x3f, 0x80, 0x00, 0x00, // A = 1
+		0xc0, 0x00, 0x00, 0x00, // B = -2
+	}
+	if got := encodeSamples(samples); !bytes.Equal(got, want) {
+		t.Errorf("got  %x\nwant %x", got, want)
+	}
+}
+
+func TestSamplesRoundTrip(t *testing.T) {
+	tests := []Samples{
+		{Inputs: [Inputs][]float32{{}, {}}},
+		{Clock: 2000, Epoch: 1000, Inputs: [Inputs][]float32{{1, -1, 0.5}, {0, 0.25, -3}}},
+		{Clock: 1 << 40, Epoch: 1 << 39, Inputs: [Inputs][]float32{{4}, {-4}}},
+	}
+	for _, samples := range tests {
+		got, err := decodeSamples(encodeSamples(samples))
+		if err != nil {
+			t.Errorf("%+v: %v", samples, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, samples) {
+			t.Errorf("got  %+v\nwant %+v", got, samples)
+		}
+	}
+}
+
+func TestDecodeSamplesInvalid(t *testing.T) {
+	valid := encodeSamples(Samples{Inputs: [Inputs][]float32{{1, 2}, {3, 4}}})
+
+	wrongInputs := slices.Clone(valid)
+	wrongInputs[16] = 5
+
+	hugeCount := slices.Clone(valid)
+	copy(hugeCount[17:], []byte{0xff, 0xff, 0xff, 0xff})
+
+	tests := map[string][]byte{
+		"empty":        {},
+		"short header": valid[:20],
+		"wrong inputs": wrongInputs,
+		"truncated":    valid[:len(valid)-1],
+		"huge count":   hugeCount,
+	}
+	for name, b := range tests {
+		if _, err := decodeSamples(b); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestWireMessages(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() { _ = client.Close() }()
+	defer func() { _ = server.Close() }()
+
+	raw := make(chan []byte)
+	go func() {
+		buf := make([]byte, 8)
+		n, _ := io.ReadFull(server, buf)
+		raw <- buf[:n]
+	}()
+
+	go func() { _ = newWire(client).send(messageControl, []byte("Tug")) }()
+	if got, want := <-raw, []byte{0x02, 0x00, 0x00, 0x00, 0x03, 'T', 'u', 'g'}; !bytes.Equal(got, want) {
+		t.Errorf("got  %x\nwant %x", got, want)
+	}
+
+	messages := []message{
+		{kind: messageStatus, payload: []byte("Ready")},
+		{kind: messageSamples, payload: encodeSamples(Samples{Inputs: [Inputs][]float32{{1}, {2}}})},
+		{kind: messageCancel, payload: []byte{}},
+	}
+	go func() {
+		w := newWire(client)
+		for _, msg := range messages {
+			_ = w.send(msg.kind, msg.payload)
+		}
+	}()
+
+	r := newWire(server)
+	for _, want := range messages {
+		got, err := r.receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"image"
+	"slices"
+	"strings"
 
 	"gioui.org/layout"
 	"gioui.org/op"
@@ -12,17 +15,76 @@ import (
 // Example how to do custom layouting when you have a need.
 
 type Grid struct {
-	// row and column proportional size
-	Row []float64
-	Col []float64
+	// row and column sizes
+	Row []Track
+	Col []Track
+
+	// Areas names the cells, similarly to CSS grid-template-areas.
+	// Each string is a row of whitespace separated names, where "." is
+	// an unnamed cell. Cells with the same name form an area, see Area.
+	Areas []string
 
 	Gap    unit.Dp
 	Margin unit.Dp
 }
 
+// TrackKind defines how the size of a Track is calculated.
+type TrackKind byte
+
+const (
+	// TrackFraction shares the space left over from the other tracks
+	// proportionally to Value.
+	TrackFraction = TrackKind(iota)
+	// TrackFixed is Value Dp.
+	TrackFixed
+	// TrackAuto fits the largest child that spans only that track.
+	TrackAuto
+)
+
+// Track is the size of a row or a column.
+type Track struct {
+	Kind  TrackKind
+	Value float64
+}
+
+// Fr returns a track that takes a fraction of the left over space.
+func Fr(weight float64) Track { return Track{Kind: TrackFraction, Value: weight} }
+
+// Fixed returns a track of the specified size.
+func Fixed(size unit.Dp) Track { return Track{Kind: TrackFixed, Value: float64(size)} }
+
+// Auto returns a track that fits its content.
+func Auto() Track { return Track{Kind: TrackAuto} }
+
+// Repeat returns n copies of the track.
+func Repeat(n int, track Track) []Track {
+	tracks := make([]Track, n)
+	for i := range tracks {
+		tracks[i] = track
+	}
+	return tracks
+}
+
+// Alignment positions a widget within its span on one axis.
+type Alignment byte
+
+const (
+	// Stretch forces the widget to fill the span.
+	Stretch = Alignment(iota)
+	Start
+	Middle
+	End
+)
+
 type Span struct {
 	Min, Max image.Point
 	Widget   layout.Widget
+
+	// Name refers to an area in Grid.Areas, instead of Min and Max.
+	Name string
+
+	// AlignX and AlignY position the widget within the span.
+	AlignX, AlignY Alignment
 }
 
 func CellAt(row, col int, w layout.Widget) Span {
@@ -49,12 +111,34 @@ func CellCols(row, col0, col1 int, w layout.Widget) Span {
 	}
 }
 
+// Area places the widget in the named area of Grid.Areas.
+func Area(name string, w layout.Widget) Span {
+	return Span{Name: name, Widget: w}
+}
+
+// Align returns the span with the widget aligned within it.
+func (span Span) Align(x, y Alignment) Span {
+	span.AlignX, span.AlignY = x, y
+	return span
+}
+
 func (g Grid) Layout(gtx layout.Context, spans ...Span) layout.Dimensions {
-	var colBuffer, rowBuffer [16]float64
+	if len(g.Areas) > 0 {
+		// don't modify the caller's spans
+		spans = slices.Clone(spans)
 
-	// cumulative weights of row or column
-	col := cumulativeProp(g.Col, colBuffer[:])
-	row := cumulativeProp(g.Row, rowBuffer[:])
+		areas := parseAreas(g.Areas, len(g.Row), len(g.Col))
+		for i := range spans {
+			if spans[i].Name == "" {
+				continue
+			}
+			area, ok := areas[spans[i].Name]
+			if !ok {
+				panic(fmt.Sprintf("grid: unknown area %q", spans[i].Name))
+			}
+			spans[i].Min, spans[i].Max = area.Min, area.Max
+		}
+	}
 
 	// total size of the context
 	size := gtx.Constraints.Max
@@ -68,12 +152,36 @@ func (g Grid) Layout(gtx layout.Context, spans ...Span) layout.Dimensions {
 		Y: size.Y - gap*(len(g.Row)-1) - margin*2,
 	}
 
+	var colBuffer, rowBuffer [16]int
+
+	// columns are resolved first, so that auto rows can be measured
+	// with the final column widths
+	col := resolveTracks(gtx, g.Col, display.X, colBuffer[:], func(i int) int {
+		width := 0
+		for _, span := range spans {
+			if span.Min.X == i && span.Max.X == i {
+				width = max(width, measure(gtx, span.Widget, image.Pt(display.X, display.Y)).X)
+			}
+		}
+		return width
+	})
+	row := resolveTracks(gtx, g.Row, display.Y, rowBuffer[:], func(i int) int {
+		height := 0
+		for _, span := range spans {
+			if span.Min.Y == i && span.Max.Y == i {
+				width := col[span.Max.X+1] - col[span.Min.X] + gap*(span.Max.X-span.Min.X)
+				height = max(height, measure(gtx, span.Widget, image.Pt(width, display.Y)).Y)
+			}
+		}
+		return height
+	})
+
 	// calculates the coordinates based on the cell coordinates.
 	// bottomRight = 1, means it should calculate the bottom right corner of the cell.
 	cellPosition := func(p image.Point, bottomRight int) image.Point {
 		return image.Point{
-			X: margin + int(col[p.X+bottomRight]*float64(display.X)+float64(gap*p.X)),
-			Y: margin + int(row[p.Y+bottomRight]*float64(display.Y)+float64(gap*p.Y)),
+			X: margin + col[p.X+bottomRight] + gap*p.X,
+			Y: margin + row[p.Y+bottomRight] + gap*p.Y,
 		}
 	}
 
@@ -88,7 +196,28 @@ func (g Grid) Layout(gtx layout.Context, spans ...Span) layout.Dimensions {
 
 			gtx := gtx
 			gtx.Constraints = layout.Exact(size)
-			_ = span.Widget(gtx)
+			if span.AlignX == Stretch && span.AlignY == Stretch {
+				_ = span.Widget(gtx)
+				return
+			}
+
+			if span.AlignX != Stretch {
+				gtx.Constraints.Min.X = 0
+			}
+			if span.AlignY != Stretch {
+				gtx.Constraints.Min.Y = 0
+			}
+
+			macro := op.Record(gtx.Ops)
+			dims := span.Widget(gtx)
+			call := macro.Stop()
+
+			offset := image.Point{
+				X: span.AlignX.offset(dims.Size.X, size.X),
+				Y: span.AlignY.offset(dims.Size.Y, size.Y),
+			}
+			defer op.Offset(offset).Push(gtx.Ops).Pop()
+			call.Add(gtx.Ops)
 		}()
 	}
 
@@ -97,20 +226,111 @@ func (g Grid) Layout(gtx layout.Context, spans ...Span) layout.Dimensions {
 	}
 }
 
-func cumulativeProp(in []float64, out []float64) []float64 {
-	if cap(out) < len(in)+1 {
-		out = make([]float64, len(in)+1)
+func (align Alignment) offset(size, space int) int {
+	switch align {
+	case Middle:
+		return (space - size) / 2
+	case End:
+		return space - size
+	default:
+		return 0
+	}
+}
+
+// resolveTracks returns the cumulative positions of the tracks in pixels.
+// content is used to measure Auto tracks.
+func resolveTracks(gtx layout.Context, tracks []Track, space int, out []int, content func(i int) int) []int {
+	if cap(out) < len(tracks)+1 {
+		out = make([]int, len(tracks)+1)
 	} else {
-		out = out[:len(in)+1]
+		out = out[:len(tracks)+1]
 	}
-	cum := 0.0
-	for i, r := range in {
-		out[i] = cum
-		cum += r
+
+	// out temporarily holds the sizes, starting from index 1
+	used, fractions := 0, 0.0
+	for i, track := range tracks {
+		switch track.Kind {
+		case TrackFixed:
+			out[i+1] = gtx.Metric.Dp(unit.Dp(track.Value))
+		case TrackAuto:
+			out[i+1] = content(i)
+		case TrackFraction:
+			out[i+1] = 0
+			fractions += track.Value
+			continue
+		}
+		used += out[i+1]
+	}
+
+	remaining := max(space-used, 0)
+	if fractions > 0 {
+		cum := 0.0
+		for i, track := range tracks {
+			if track.Kind != TrackFraction {
+				continue
+			}
+			// distribute using cumulative rounding, so that the sizes add up
+			before := int(cum / fractions * float64(remaining))
+			cum += track.Value
+			out[i+1] = int(cum/fractions*float64(remaining)) - before
+		}
 	}
-	out[len(out)-1] = cum
-	for i := range out {
-		out[i] /= cum
+
+	out[0] = 0
+	for i := 1; i < len(out); i++ {
+		out[i] += out[i-1]
 	}
 	return out
 }
+
+// measure returns the size of the widget, without drawing it or
+// handling its events.
+func measure(gtx layout.Context, w layout.Widget, max image.Point) image.Point {
+	gtx = gtx.Disabled()
+	gtx.Constraints = layout.Constraints{Max: max}
+
+	macro := op.Record(gtx.Ops)
+	dims := w(gtx)
+	_ = macro.Stop()
+	return dims.Size
+}
+
+// parseAreas returns the cell bounds of each named area,
+// it panics when the template doesn't match the number of tracks
+// or when an area is not rectangular.
+func parseAreas(rows []string, rowCount, colCount int) map[string]image.Rectangle {
+	if len(rows) != rowCount {
+		panic(fmt.Sprintf("grid: areas have %d rows, expected %d", len(rows), rowCount))
+	}
+
+	areas := map[string]image.Rectangle{}
+	cells := map[string]int{}
+	for y, row := range rows {
+		names := strings.Fields(row)
+		if len(names) != colCount {
+			panic(fmt.Sprintf("grid: areas row %d has %d columns, expected %d", y, len(names), colCount))
+		}
+		for x, name := range names {
+			if name == "." {
+				continue
+			}
+			cell := image.Rect(x, y, x, y)
+			if area, ok := areas[name]; ok {
+				cell = image.Rectangle{
+					Min: image.Pt(min(area.Min.X, x), min(area.Min.Y, y)),
+					Max: image.Pt(max(area.Max.X, x), max(area.Max.Y, y)),
+				}
+			}
+			areas[name] = cell
+			cells[name]++
+		}
+	}
+
+	for name, area := range areas {
+		// Max is inclusive
+		if cells[name] != (area.Dx()+1)*(area.Dy()+1) {
+			panic(fmt.Sprintf("grid: area %q is not rectangular", name))
+		}
+	}
+	return areas
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/egonelbre/expgio/oscillator/generator"
+	"github.com/egonelbre/expgio/oscillator/scope"
+)
+
+// HistoryBar pauses the display and scrubs through the recorded frames.
+type HistoryBar struct {
+	Recorder *generator.Recorder
+	Display  *scope.Display
+
+	Paused bool
+
+	// frames is the history at the time of pausing.
+	frames []generator.Frame
+	shown  int
+
+	Pause    widget.Clickable
+	Position widget.Float
+}
+
+func (history *HistoryBar) update(gtx layout.Context) {
+	if history.Pause.Clicked(gtx) {
+		history.Paused = !history.Paused
+		history.frames = nil
+		history.Position.Value = 1
+		if history.Paused && history.Recorder != nil {
+			history.frames = history.Recorder.Frames()
+			history.shown = len(history.frames) - 1
+		}
+		// the data jumps in time, so the previous trigger isn't relevant
+		history.Display.Reset()
+	}
+
+	if !history.Paused {
+		history.Position.Value = 1
+		return
+	}
+	if history.Position.Update(gtx) && len(history.frames) > 0 {
+		i := int(math.Round(float64(history.Position.Value) * float64(len(history.frames)-1)))
+		if i != history.shown {
+			history.shown = i
+			history.Display.Reset()
+			history.Display.Push(generator.DataAt(history.frames, i))
+		}
+	}
+}
+
+func (history *HistoryBar) Layout(th *material.Theme, gtx layout.Context) layout.Dimensions {
+	history.update(gtx)
+
+	caption := "Pause"
+	if history.Paused {
+		caption = "Resume"
+	}
+
+	return layout.UniformInset(8).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(material.Button(th, &history.Pause, caption).Layout),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				if !history.Paused || len(history.frames) < 2 {
+					gtx = gtx.Disabled()
+				}
+				return layout.Inset{Left: 8, Right: 8}.Layout(gtx, material.Slider(th, &history.Position).Layout)
+			}),
+			layout.Rigid(material.Body1(th, history.describe()).Layout),
+		)
+	})
+}
+
+// describe returns the position of the shown frame relative to the latest.
+func (history *HistoryBar) describe() string {
+	if !history.Paused {
+		return "LIVE"
+	}
+	if len(history.frames) == 0 {
+		return "PAUSED"
+	}
+
+	frame := history.frames[history.shown]
+	latest := history.frames[len(history.frames)-1]
+	return fmt.Sprintf("%d/%d %+.2fs %v",
+		history.shown+1, len(history.frames),
+		frame.Time.Sub(latest.Time).Seconds(),
+		frame.Config.Functions)
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"image/color"
+	"io"
 	"os"
 	"sync"
 
@@ -20,8 +22,49 @@ import (
 )
 
 func main() {
+	wav := flag.String("wav", "", "add WAV `file` as a signal source")
+	record := flag.String("record", "", "record all frames to `file`")
+	replay := flag.String("replay", "", "replay frames from a recorded `file`")
+	history := flag.Int("history", 300, "number of frames kept for scrubbing")
+	connect := flag.String("connect", "", "connect to a generator server at `address`")
+	flag.Parse()
+
+	if *wav != "" {
+		source, err := generator.OpenWAV(*wav)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		generator.Register("WAV", func() generator.Source { return source })
+	}
+
 	ctx := context.TODO()
 	gen := generator.NewClient(generator.DefaultConfig)
+	if *connect != "" {
+		gen = generator.NewRemoteClient(*connect, generator.DefaultConfig)
+	}
+	if *replay != "" {
+		frames, err := readRecording(*replay)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		gen = generator.NewReplayClient(frames)
+	}
+
+	// a nil *os.File must not end up in the io.Writer
+	var recording *os.File
+	var out io.Writer
+	if *record != "" {
+		var err error
+		recording, err = os.Create(*record)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		out = recording
+	}
+	gen.Recorder = generator.NewRecorder(*history, out)
 
 	ui := NewUI(gen)
 
@@ -32,7 +75,16 @@ func main() {
 		w.Option(
 			app.Title("Oscillator"),
 		)
-		if err := ui.Run(w); err != nil {
+		err := ui.Run(w)
+		if cerr := gen.Recorder.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		if recording != nil {
+			if cerr := recording.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
@@ -42,25 +94,41 @@ func main() {
 	app.Main()
 }
 
+func readRecording(path string) ([]generator.Frame, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	return generator.ReadRecording(f)
+}
+
 type UI struct {
 	theme    *material.Theme
 	status   *StatusBar
+	measure  *MeasurementBar
+	history  *HistoryBar
 	controls *Controls
 	scope    *scope.Display
+	spectrum *scope.Spectrum
 
 	generator *generator.Client
 }
 
 func NewUI(gen *generator.Client) *UI {
-	return &UI{
-		theme: material.NewTheme(),
-
-		status:   NewStatus(),
-		controls: NewControls(gen),
-		scope:    scope.NewDisplay(),
+	ui := &UI{
+		theme:  material.NewTheme(),
+		status: NewStatus(),
 
 		generator: gen,
 	}
+	ui.scope = scope.NewDisplay()
+	ui.spectrum = scope.NewSpectrum(ui.scope)
+	ui.measure = &MeasurementBar{Display: ui.scope}
+	ui.history = &HistoryBar{Recorder: gen.Recorder, Display: ui.scope}
+	ui.controls = NewControls(gen, ui.scope, ui.spectrum)
+	return ui
 }
 
 type AsyncValue[T any] struct {
@@ -97,11 +165,46 @@ func (async *AsyncValue[T]) Check(onChange func(v T)) {
 	onChange(async.value)
 }
 
+// AsyncQueue collects all the values from a channel, unlike AsyncValue
+// which only keeps the latest.
+type AsyncQueue[T any] struct {
+	mu     sync.Mutex
+	values []T
+}
+
+func AsyncReadAll[T any](data <-chan T, updated func()) *AsyncQueue[T] {
+	async := &AsyncQueue[T]{}
+	go async.Read(data, updated)
+	return async
+}
+
+func (async *AsyncQueue[T]) Read(data <-chan T, updated func()) {
+	for v := range data {
+		async.mu.Lock()
+		async.values = append(async.values, v)
+		async.mu.Unlock()
+
+		updated()
+	}
+}
+
+func (async *AsyncQueue[T]) Check(onValue func(v T)) {
+	async.mu.Lock()
+	values := async.values
+	async.values = nil
+	async.mu.Unlock()
+
+	for _, v := range values {
+		onValue(v)
+	}
+}
+
 func (ui *UI) Run(w *app.Window) error {
 	var ops op.Ops
 
 	asyncStatus := AsyncRead(ui.generator.Status, w.Invalidate)
 	asyncData := AsyncRead(ui.generator.Data, w.Invalidate)
+	asyncProgress := AsyncReadAll(ui.generator.Progress, w.Invalidate)
 
 	for {
 		switch e := w.Event().(type) {
@@ -112,8 +215,16 @@ func (ui *UI) Run(w *app.Window) error {
 			asyncStatus.Check(func(v generator.Status) {
 				ui.status.Current = v
 			})
+			asyncProgress.Check(func(v generator.Progress) {
+				ui.controls.Progress[v.Control] = v
+				if v.Final() {
+					ui.status.Current = generator.Status(v.String())
+				}
+			})
 			asyncData.Check(func(v generator.Data) {
-				ui.scope.Data = v
+				if !ui.history.Paused {
+					ui.scope.Push(v)
+				}
 			})
 
 			gtx := app.NewContext(&ops, e)
@@ -126,14 +237,39 @@ func (ui *UI) Run(w *app.Window) error {
 func (ui *UI) Layout(gtx layout.Context) layout.Dimensions {
 	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			return ui.status.Layout(ui.theme, gtx)
+			return layout.Flex{Spacing: layout.SpaceBetween}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return ui.status.Layout(ui.theme, gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return ui.measure.Layout(ui.theme, gtx)
+				}),
+			)
 		}),
 		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
-			return ui.scope.Layout(ui.theme, gtx)
+			switch ui.controls.View {
+			case ViewSpectrum:
+				return ui.spectrum.Layout(ui.theme, gtx)
+			case ViewSplit:
+				return layout.Flex{Spacing: layout.SpaceBetween}.Layout(gtx,
+					layout.Flexed(0.5, func(gtx layout.Context) layout.Dimensions {
+						return ui.scope.Layout(ui.theme, gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: 2}.Layout),
+					layout.Flexed(0.5, func(gtx layout.Context) layout.Dimensions {
+						return ui.spectrum.Layout(ui.theme, gtx)
+					}),
+				)
+			default:
+				return ui.scope.Layout(ui.theme, gtx)
+			}
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return ui.history.Layout(ui.theme, gtx)
 		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			// Let's hardcode the height
-			gtx.Constraints.Max.Y = gtx.Metric.Sp(150)
+			gtx.Constraints.Max.Y = gtx.Metric.Sp(220)
 			gtx.Constraints.Min.Y = gtx.Constraints.Max.Y
 
 			return ui.controls.Layout(ui.theme, gtx)
@@ -153,32 +289,117 @@ func (status *StatusBar) Layout(th *material.Theme, gtx layout.Context) layout.D
 	return material.H4(th, string(status.Current)).Layout(gtx)
 }
 
+// View selects which displays are shown.
+type View byte
+
+const (
+	ViewTime = View(iota)
+	ViewSpectrum
+	ViewSplit
+)
+
+func (view View) String() string {
+	switch view {
+	case ViewTime:
+		return "TIME"
+	case ViewSpectrum:
+		return "FREQ"
+	case ViewSplit:
+		return "BOTH"
+	default:
+		return "???"
+	}
+}
+
+func (View) Options() []View {
+	return []View{ViewTime, ViewSpectrum, ViewSplit}
+}
+
+// MeasurementBar shows the automatic measurements of the visible channels.
+type MeasurementBar struct {
+	Display *scope.Display
+}
+
+func (bar *MeasurementBar) Layout(th *material.Theme, gtx layout.Context) layout.Dimensions {
+	var children []layout.FlexChild
+	for c, channel := range bar.Display.Channels {
+		if !channel.Visible {
+			continue
+		}
+		text := generator.Channel(c).String() + "  " + bar.Display.Measurements[c].String()
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Caption(th, text)
+			label.Color = channel.Color.NRGBA()
+			return label.Layout(gtx)
+		}))
+	}
+	return layout.Inset{Right: 8}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical, Alignment: layout.End}.Layout(gtx, children...)
+	})
+}
+
 type Controls struct {
 	Generator *generator.Client
+	Display   *scope.Display
+	Spectrum  *scope.Spectrum
+
+	View       View
+	SelectView Spin[View]
+	Window     Spin[scope.FFTWindow]
 
 	Active  generator.Config
 	Pending generator.Config
 
-	Function Spin[generator.Function]
-	Scale    Spin[generator.Scale]
+	// Config edits Pending and Trigger edits the display trigger.
+	Config  *Form
+	Trigger *Form
+
+	// Channel is the channel being edited by VoltDiv, Offset, Color and Visible.
+	Channel generator.Channel
+	Select  Spin[generator.Channel]
+	VoltDiv Spin[scope.VoltDiv]
+	Offset  Spin[scope.Offset]
+	Color   Spin[scope.TraceColor]
+	Visible widget.Bool
+	Cursors widget.Bool
+
+	TimeDiv Spin[scope.TimeDiv]
 
+	// Progress is the latest progress of each control.
+	Progress map[generator.Control]generator.Progress
+
+	Arm   widget.Clickable
 	Ping  widget.Clickable
 	Trace widget.Clickable
 	Tune  widget.Clickable
 }
 
-func NewControls(gen *generator.Client) *Controls {
+func NewControls(gen *generator.Client, display *scope.Display, spectrum *scope.Spectrum) *Controls {
 	initial := gen.InitialConfig()
 
 	panel := &Controls{
 		Generator: gen,
+		Display:   display,
+		Spectrum:  spectrum,
 
 		Active:  initial,
 		Pending: initial,
+
+		Progress: map[generator.Control]generator.Progress{},
 	}
 
-	panel.Function.Current = &panel.Pending.Function
-	panel.Scale.Current = &panel.Pending.Scale
+	panel.Config = NewForm(&panel.Pending)
+	panel.Config.Gap = 8
+	panel.Trigger = NewForm(&display.Trigger)
+	panel.Trigger.Gap = 8
+
+	panel.SelectView.Current = &panel.View
+	panel.Window.Current = &spectrum.Window
+
+	panel.Select.Label = "CH"
+	panel.Select.Current = &panel.Channel
+
+	panel.TimeDiv.Current = &display.TimePerDiv
 
 	return panel
 }
@@ -199,33 +420,96 @@ func (controls *Controls) Layout(th *material.Theme, gtx layout.Context) layout.
 		}
 	}
 
-	if controls.Ping.Clicked(gtx) {
-		controls.Generator.Control(generator.Ping)
+	if controls.Arm.Clicked(gtx) {
+		controls.Display.Arm()
 	}
-	if controls.Tune.Clicked(gtx) {
-		controls.Generator.Control(generator.Tune)
-	}
-	if controls.Trace.Clicked(gtx) {
-		controls.Generator.Control(generator.Trace)
+
+	// point the channel controls to the selected channel
+	channel := &controls.Display.Channels[controls.Channel]
+	controls.VoltDiv.Current = &channel.VoltsPerDiv
+	controls.Offset.Current = &channel.Offset
+	controls.Color.Current = &channel.Color
+	controls.Visible.Value = channel.Visible
+	if controls.Visible.Update(gtx) {
+		channel.Visible = controls.Visible.Value
 	}
+	controls.Display.Cursors.Channel = controls.Channel
+	controls.Display.Cursors.Visible = controls.Cursors.Value
 
 	return Grid{
-		Row: []float64{1, 1, 1},
-		Col: []float64{1, 1, 1},
+		Row: append([]Track{Auto()}, Repeat(4, Fr(1))...),
+		Col: Repeat(5, Fr(1)),
+		Areas: []string{
+			"hconfig hconfig hscope hdevice hdisplay",
+			"config trigger timediv ping  view",
+			"config trigger channel trace window",
+			"config trigger voltdiv tune  cursors",
+			"arm    trigger offset  color .",
+		},
 		Gap: 8, Margin: 8,
 	}.Layout(gtx,
-		CellAt(0, 0, themed(controls.Function.Layout)),
-		CellAt(1, 0, themed(controls.Scale.Layout)),
+		Area("hconfig", material.Caption(th, "GENERATOR & TRIGGER").Layout).Align(Start, Start),
+		Area("hscope", material.Caption(th, "TIMEBASE & CHANNEL").Layout).Align(Start, Start),
+		Area("hdevice", material.Caption(th, "DEVICE").Layout).Align(Start, Start),
+		Area("hdisplay", material.Caption(th, "DISPLAY").Layout).Align(Start, Start),
+
+		Area("config", themed(controls.Config.Layout)),
+		Area("trigger", themed(controls.Trigger.Layout)),
+		Area("arm", material.Button(th, &controls.Arm, "Arm").Layout),
+
+		Area("timediv", themed(controls.TimeDiv.Layout)),
+
+		Area("channel", themed(controls.Select.Layout)),
+		Area("voltdiv", themed(controls.VoltDiv.Layout)),
+		Area("offset", themed(controls.Offset.Layout)),
+		Area("color", func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(material.CheckBox(th, &controls.Visible, "").Layout),
+				layout.Flexed(1, themed(controls.Color.Layout)),
+			)
+		}),
 
-		// non functional buttons for demo
-		CellRows(0, 2, 1, ColorBox{G: 0x88, B: 0x88, A: 0x88}.Layout),
+		Area("ping", controls.control(th, generator.Ping, &controls.Ping)),
+		Area("trace", controls.control(th, generator.Trace, &controls.Trace)),
+		Area("tune", controls.control(th, generator.Tune, &controls.Tune)),
 
-		CellAt(0, 2, material.Button(th, &controls.Ping, "Ping").Layout),
-		CellAt(1, 2, material.Button(th, &controls.Trace, "Trace").Layout),
-		CellAt(2, 2, material.Button(th, &controls.Tune, "Tune").Layout),
+		Area("view", themed(controls.SelectView.Layout)),
+		Area("window", themed(controls.Window.Layout)),
+		Area("cursors", material.CheckBox(th, &controls.Cursors, "Cursors").Layout).Align(Start, Middle),
 	)
 }
 
+// control returns a button that starts the control, or cancels it when it's running.
+func (controls *Controls) control(th *material.Theme, control generator.Control, click *widget.Clickable) layout.Widget {
+	return func(gtx layout.Context) layout.Dimensions {
+		progress, ok := controls.Progress[control]
+		running := ok && !progress.Final()
+
+		if click.Clicked(gtx) {
+			if running {
+				controls.Generator.Cancel(control)
+			} else {
+				controls.Generator.Control(control)
+			}
+		}
+
+		caption := string(control)
+		if running {
+			caption = fmt.Sprintf("Stop %s %.0f%%", control, progress.Done*100)
+		}
+
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Flexed(1, material.Button(th, click, caption).Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !running {
+					return layout.Dimensions{}
+				}
+				return material.ProgressBar(th, progress.Done).Layout(gtx)
+			}),
+		)
+	}
+}
+
 // useful for stubbig out things
 
 type ColorBox color.NRGBA
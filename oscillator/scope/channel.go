@@ -0,0 +1,78 @@
+package scope
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// Channel configures how a single trace is displayed.
+type Channel struct {
+	Color       TraceColor
+	VoltsPerDiv VoltDiv
+	Offset      Offset
+	Visible     bool
+}
+
+// TraceColor is the color of a trace.
+type TraceColor byte
+
+const (
+	Yellow = TraceColor(iota)
+	Cyan
+	Magenta
+	Green
+	Orange
+	White
+)
+
+func (c TraceColor) String() string {
+	switch c {
+	case Yellow:
+		return "YEL"
+	case Cyan:
+		return "CYN"
+	case Magenta:
+		return "MAG"
+	case Green:
+		return "GRN"
+	case Orange:
+		return "ORG"
+	case White:
+		return "WHT"
+	default:
+		return "???"
+	}
+}
+
+func (TraceColor) Options() []TraceColor {
+	return []TraceColor{Yellow, Cyan, Magenta, Green, Orange, White}
+}
+
+// NRGBA returns the color value.
+func (c TraceColor) NRGBA() color.NRGBA {
+	switch c {
+	case Yellow:
+		return color.NRGBA{R: 0xFF, G: 0xE0, B: 0x40, A: 0xFF}
+	case Cyan:
+		return color.NRGBA{R: 0x40, G: 0xE0, B: 0xFF, A: 0xFF}
+	case Magenta:
+		return color.NRGBA{R: 0xFF, G: 0x50, B: 0xE0, A: 0xFF}
+	case Green:
+		return color.NRGBA{R: 0x60, G: 0xFF, B: 0x60, A: 0xFF}
+	case Orange:
+		return color.NRGBA{R: 0xFF, G: 0x90, B: 0x30, A: 0xFF}
+	default:
+		return white
+	}
+}
+
+// Offset is the vertical position of a trace in divisions.
+type Offset float32
+
+func (offset Offset) String() string {
+	return fmt.Sprintf("POS %+gdiv", float32(offset))
+}
+
+func (Offset) Options() []Offset {
+	return []Offset{-4, -3, -2, -1, 0, 1, 2, 3, 4}
+}
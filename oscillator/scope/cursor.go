@@ -0,0 +1,125 @@
+package scope
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"github.com/egonelbre/expgio/oscillator/generator"
+)
+
+// Cursors are a pair of time and a pair of level markers for
+// measuring the trace by hand.
+type Cursors struct {
+	Visible bool
+
+	// T1 and T2 are in divisions from the left edge.
+	T1, T2 float32
+	// V1 and V2 are in divisions from the center, positive upwards.
+	V1, V2 float32
+
+	// Channel is used for converting V1 and V2 to volts.
+	Channel generator.Channel
+
+	dragging *float32
+}
+
+// cursorGrab is how close the pointer needs to be to drag a cursor.
+const cursorGrab = unit.Dp(8)
+
+var cursorColor = color.NRGBA{R: 0x80, G: 0xC0, B: 0xFF, A: 0xC0}
+
+func NewCursors() Cursors {
+	return Cursors{T1: 2, T2: 6, V1: 2, V2: -2}
+}
+
+// update handles dragging the cursors.
+func (cursors *Cursors) update(gtx layout.Context, div f32.Point) {
+	size := gtx.Constraints.Max
+	for {
+		ev, ok := gtx.Event(pointer.Filter{
+			Target: cursors,
+			Kinds:  pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel,
+		})
+		if !ok {
+			break
+		}
+		e, ok := ev.(pointer.Event)
+		if !ok {
+			continue
+		}
+
+		switch e.Kind {
+		case pointer.Press:
+			cursors.dragging = cursors.nearest(e.Position, div, size, float32(gtx.Metric.Dp(cursorGrab)))
+		case pointer.Drag:
+			switch cursors.dragging {
+			case nil:
+			case &cursors.T1, &cursors.T2:
+				*cursors.dragging = clampf(e.Position.X, 0, float32(size.X)) / div.X
+			case &cursors.V1, &cursors.V2:
+				*cursors.dragging = (float32(size.Y)/2 - clampf(e.Position.Y, 0, float32(size.Y))) / div.Y
+			}
+		case pointer.Release, pointer.Cancel:
+			cursors.dragging = nil
+		}
+	}
+}
+
+// nearest returns the cursor closest to pos, when it's within grab pixels.
+func (cursors *Cursors) nearest(pos, div f32.Point, size image.Point, grab float32) *float32 {
+	var best *float32
+	distance := grab
+	check := func(v *float32, d float32) {
+		d = max(d, -d)
+		if d <= distance {
+			best, distance = v, d
+		}
+	}
+	check(&cursors.T1, pos.X-cursors.T1*div.X)
+	check(&cursors.T2, pos.X-cursors.T2*div.X)
+	check(&cursors.V1, pos.Y-(float32(size.Y)/2-cursors.V1*div.Y))
+	check(&cursors.V2, pos.Y-(float32(size.Y)/2-cursors.V2*div.Y))
+	return best
+}
+
+// Layout draws the cursors and the delta readouts.
+func (cursors *Cursors) Layout(th *material.Theme, gtx layout.Context, display *Display, div f32.Point) {
+	if !cursors.Visible {
+		return
+	}
+	cursors.update(gtx, div)
+
+	size := gtx.Constraints.Max
+	event.Op(gtx.Ops, cursors)
+
+	line := max(gtx.Metric.Dp(1), 1)
+	for _, t := range []float32{cursors.T1, cursors.T2} {
+		x := int(t * div.X)
+		paint.FillShape(gtx.Ops, cursorColor, clip.Rect{Min: image.Pt(x, 0), Max: image.Pt(x+line, size.Y)}.Op())
+	}
+	for _, v := range []float32{cursors.V1, cursors.V2} {
+		y := int(float32(size.Y)/2 - v*div.Y)
+		paint.FillShape(gtx.Ops, cursorColor, clip.Rect{Min: image.Pt(0, y), Max: image.Pt(size.X, y+line)}.Op())
+	}
+
+	dt := (cursors.T2 - cursors.T1) * float32(display.TimePerDiv)
+	dv := (cursors.V1 - cursors.V2) * float32(display.Channels[cursors.Channel].VoltsPerDiv)
+	readout := "ΔT " + formatUnit(dt, "s") +
+		"  1/ΔT " + formatUnit(1/max(dt, -dt), "Hz") +
+		"  ΔV(" + cursors.Channel.String() + ") " + formatUnit(dv, "V")
+
+	layoutText(th, gtx, image.Pt(gtx.Metric.Dp(4), size.Y-gtx.Metric.Dp(20)), readout, cursorColor)
+}
+
+func clampf(v, low, high float32) float32 {
+	return min(max(v, low), high)
+}
@@ -0,0 +1,116 @@
+package scope
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// FFTWindow is the window function applied before the FFT.
+type FFTWindow byte
+
+const (
+	Hann = FFTWindow(iota)
+	Hamming
+	Blackman
+)
+
+func (window FFTWindow) String() string {
+	switch window {
+	case Hann:
+		return "HANN"
+	case Hamming:
+		return "HAMM"
+	case Blackman:
+		return "BLKM"
+	default:
+		return "???"
+	}
+}
+
+func (FFTWindow) Options() []FFTWindow {
+	return []FFTWindow{Hann, Hamming, Blackman}
+}
+
+// At returns the window coefficient for sample i out of n.
+func (window FFTWindow) At(i, n int) float64 {
+	if n <= 1 {
+		return 1
+	}
+	p := 2 * math.Pi * float64(i) / float64(n-1)
+	switch window {
+	case Hann:
+		return 0.5 - 0.5*math.Cos(p)
+	case Hamming:
+		return 0.54 - 0.46*math.Cos(p)
+	case Blackman:
+		return 0.42 - 0.5*math.Cos(p) + 0.08*math.Cos(2*p)
+	default:
+		return 1
+	}
+}
+
+// magnitudes calculates the single-sided amplitude spectrum of values in dB,
+// where a full scale sine wave of amplitude 1 is 0dB.
+//
+// values is zero-padded to a power of two, the result contains
+// the bins from 0 up to the Nyquist frequency.
+func magnitudes(values []float64, window FFTWindow) []float64 {
+	n := 1
+	for n < len(values) {
+		n *= 2
+	}
+
+	buf := make([]complex128, n)
+	gain := 0.0
+	for i, v := range values {
+		w := window.At(i, len(values))
+		buf[i] = complex(v*w, 0)
+		gain += w
+	}
+	if gain == 0 {
+		// e.g. a Hann window is zero everywhere for two samples
+		gain = 1
+	}
+	fft(buf)
+
+	result := make([]float64, n/2+1)
+	for i := range result {
+		amplitude := cmplx.Abs(buf[i]) / gain
+		if i != 0 && i != n/2 {
+			amplitude *= 2
+		}
+		result[i] = 20 * math.Log10(max(amplitude, 1e-12))
+	}
+	return result
+}
+
+// fft calculates an in-place radix-2 FFT, len(x) must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+	if n <= 1 {
+		return
+	}
+
+	// bit reversal permutation
+	shift := 64 - uint(bits.TrailingZeros(uint(n)))
+	for i := range x {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size *= 2 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := range size / 2 {
+				a, b := x[start+k], x[start+k+size/2]*w
+				x[start+k] = a + b
+				x[start+k+size/2] = a - b
+				w *= step
+			}
+		}
+	}
+}
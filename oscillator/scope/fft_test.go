@@ -0,0 +1,86 @@
+package scope
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestMagnitudes(t *testing.T) {
+	const n = 1024
+	values := func(fn func(t float64) float64) []float64 {
+		result := make([]float64, n)
+		for i := range result {
+			result[i] = fn(float64(i))
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		values []float64
+		// want is the magnitude in dB at each bin
+		want  map[int]float64
+		peaks []int
+	}{
+		{"sine", values(sine(64.0/n, 1)), map[int]float64{64: 0}, []int{64}},
+		{"half sine", values(sine(100.0/n, 0.5)), map[int]float64{100: -6.02}, []int{100}},
+		{"square", values(func(t float64) float64 { return 2*square(32.0/n, 1)(t) - 1 }),
+			// the odd harmonics have amplitude 4/(πk)
+			map[int]float64{
+				32:  20 * math.Log10(4/math.Pi),
+				96:  20 * math.Log10(4/(3*math.Pi)),
+				160: 20 * math.Log10(4/(5*math.Pi)),
+			}, []int{32, 96, 160}},
+	}
+
+	for _, test := range tests {
+		for _, window := range []FFTWindow{Hann, Hamming, Blackman} {
+			magnitude := magnitudes(test.values, window)
+			if len(magnitude) != n/2+1 {
+				t.Fatalf("%s %v: got %d bins", test.name, window, len(magnitude))
+			}
+			for bin, want := range test.want {
+				if got := magnitude[bin]; math.Abs(got-want) > 0.5 {
+					t.Errorf("%s %v: bin %d got %.2fdB, want %.2fdB", test.name, window, bin, got, want)
+				}
+			}
+			if got := peaks(magnitude, len(test.peaks)); !slices.Equal(got, test.peaks) {
+				t.Errorf("%s %v: peaks got %v, want %v", test.name, window, got, test.peaks)
+			}
+		}
+	}
+}
+
+func TestMagnitudesShort(t *testing.T) {
+	for _, values := range [][]float64{{1}, {1, 1}, {1, -1, 1}} {
+		for _, window := range []FFTWindow{Hann, Hamming, Blackman} {
+			for bin, db := range magnitudes(values, window) {
+				if math.IsNaN(db) || math.IsInf(db, 0) {
+					t.Errorf("%v %v: bin %d is %v", values, window, bin, db)
+				}
+			}
+		}
+	}
+}
+
+func TestPeaks(t *testing.T) {
+	tests := []struct {
+		magnitude []float64
+		n         int
+		want      []int
+	}{
+		{nil, 3, nil},
+		{[]float64{0, -10, -20}, 3, nil},
+		{[]float64{0, -10, -5, -20, -3, -30}, 3, []int{4, 2}},
+		{[]float64{0, -10, -5, -20, -3, -30}, 1, []int{4}},
+		// below the floor
+		{[]float64{0, -100, -90, -100}, 3, nil},
+	}
+
+	for _, test := range tests {
+		if got := peaks(test.magnitude, test.n); !slices.Equal(got, test.want) {
+			t.Errorf("%v: got %v, want %v", test.magnitude, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package scope
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/egonelbre/expgio/oscillator/generator"
+)
+
+// Measurement contains automatic measurements of a trace.
+//
+// Values that can't be determined from the trace are NaN.
+type Measurement struct {
+	Frequency  float32 // Hz
+	Period     float32 // seconds
+	PeakToPeak float32
+	RMS        float32
+	Mean       float32
+	RiseTime   float32 // seconds, from 10% to 90%
+}
+
+// Measure calculates the measurements of values.
+func Measure(values []generator.Point) Measurement {
+	nan := float32(math.NaN())
+	m := Measurement{
+		Frequency:  nan,
+		Period:     nan,
+		PeakToPeak: nan,
+		RMS:        nan,
+		Mean:       nan,
+		RiseTime:   nan,
+	}
+	if len(values) == 0 {
+		return m
+	}
+
+	low, high := values[0].Y, values[0].Y
+	var sum, sumSquares float64
+	for _, v := range values {
+		low, high = min(low, v.Y), max(high, v.Y)
+		sum += float64(v.Y)
+		sumSquares += float64(v.Y) * float64(v.Y)
+	}
+	m.PeakToPeak = high - low
+	m.Mean = float32(sum / float64(len(values)))
+	m.RMS = float32(math.Sqrt(sumSquares / float64(len(values))))
+	if m.PeakToPeak <= 0 {
+		return m
+	}
+
+	// the levels use hysteresis, so that noise doesn't cause extra crossings
+	at := func(p float32) float32 { return low + m.PeakToPeak*p }
+	lowLevel, midLevel, highLevel := at(0.1), at(0.5), at(0.9)
+
+	var crossings []float32
+	riseStart := nan
+	armed := false
+	for i := 1; i < len(values); i++ {
+		a, b := values[i-1], values[i]
+		if b.Y <= lowLevel {
+			armed = true
+			riseStart = nan
+		}
+		if !armed {
+			continue
+		}
+		if a.Y < lowLevel && b.Y >= lowLevel {
+			riseStart = crossing(a, b, lowLevel)
+		}
+		if a.Y < midLevel && b.Y >= midLevel {
+			crossings = append(crossings, crossing(a, b, midLevel))
+		}
+		if a.Y < highLevel && b.Y >= highLevel {
+			if math.IsNaN(float64(m.RiseTime)) && !math.IsNaN(float64(riseStart)) {
+				m.RiseTime = crossing(a, b, highLevel) - riseStart
+			}
+			armed = false
+		}
+	}
+
+	if len(crossings) >= 2 {
+		m.Period = (crossings[len(crossings)-1] - crossings[0]) / float32(len(crossings)-1)
+		m.Frequency = 1 / m.Period
+	}
+
+	return m
+}
+
+// crossing returns the interpolated time when the line from a to b crosses level.
+func crossing(a, b generator.Point, level float32) float32 {
+	if a.Y == b.Y {
+		return a.X
+	}
+	return a.X + (b.X-a.X)*(level-a.Y)/(b.Y-a.Y)
+}
+
+func (m Measurement) String() string {
+	var s strings.Builder
+	field := func(name, value string) {
+		if s.Len() > 0 {
+			s.WriteString("  ")
+		}
+		s.WriteString(name)
+		s.WriteString(" ")
+		s.WriteString(value)
+	}
+
+	field("f", formatUnit(m.Frequency, "Hz"))
+	field("T", formatUnit(m.Period, "s"))
+	field("Vpp", formatUnit(m.PeakToPeak, "V"))
+	field("RMS", formatUnit(m.RMS, "V"))
+	field("Mean", formatUnit(m.Mean, "V"))
+	field("Rise", formatUnit(m.RiseTime, "s"))
+	return s.String()
+}
+
+// formatUnit formats v with an SI prefix, values below 1n are
+// rounding errors and shown as 0.
+func formatUnit(v float32, unit string) string {
+	abs := math.Abs(float64(v))
+	switch {
+	case math.IsNaN(float64(v)) || math.IsInf(float64(v), 0):
+		return "---"
+	case abs < 1e-9:
+		return "0" + unit
+	case abs < 1e-6:
+		return fmt.Sprintf("%.3gn%s", v*1e9, unit)
+	case abs < 1e-3:
+		return fmt.Sprintf("%.3gµ%s", v*1e6, unit)
+	case abs < 1:
+		return fmt.Sprintf("%.3gm%s", v*1e3, unit)
+	case abs < 1e3:
+		return fmt.Sprintf("%.3g%s", v, unit)
+	default:
+		return fmt.Sprintf("%.3gk%s", v/1e3, unit)
+	}
+}
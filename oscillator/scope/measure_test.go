@@ -0,0 +1,108 @@
+package scope
+
+import (
+	"math"
+	"testing"
+
+	"github.com/egonelbre/expgio/oscillator/generator"
+)
+
+// sampled returns n values of fn sampled every dt seconds.
+func sampled(n int, dt float64, fn func(t float64) float64) []generator.Point {
+	values := make([]generator.Point, n)
+	for i := range values {
+		t := float64(i) * dt
+		values[i] = generator.Point{X: float32(t), Y: float32(fn(t))}
+	}
+	return values
+}
+
+func sine(freq, amplitude float64) func(t float64) float64 {
+	return func(t float64) float64 { return amplitude * math.Sin(2*math.Pi*freq*t) }
+}
+
+// square alternates between 0 and high, starting high.
+func square(freq, high float64) func(t float64) float64 {
+	return func(t float64) float64 {
+		if math.Mod(t*freq+1e-9, 1) < 0.5 {
+			return high
+		}
+		return 0
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	nan := float32(math.NaN())
+	tests := []struct {
+		name   string
+		values []generator.Point
+		want   Measurement
+	}{
+		{"empty", nil, Measurement{nan, nan, nan, nan, nan, nan}},
+		{"constant", sampled(100, 1e-3, func(float64) float64 { return 0.5 }),
+			Measurement{nan, nan, 0, 0.5, 0.5, nan}},
+		{"sine", sampled(1000, 1e-4, sine(50, 1)), Measurement{
+			Frequency:  50,
+			Period:     0.02,
+			PeakToPeak: 2,
+			RMS:        float32(math.Sqrt(0.5)),
+			Mean:       0,
+			// from -0.8 to 0.8
+			RiseTime: float32(2 * math.Asin(0.8) / (2 * math.Pi * 50)),
+		}},
+		{"square", sampled(1000, 1e-4, square(100, 1)), Measurement{
+			Frequency:  100,
+			Period:     0.01,
+			PeakToPeak: 1,
+			RMS:        float32(math.Sqrt(0.5)),
+			Mean:       0.5,
+			// the edge is linear between two samples
+			RiseTime: 0.8e-4,
+		}},
+	}
+
+	for _, test := range tests {
+		got := Measure(test.values)
+		check := func(field string, got, want float32) {
+			t.Helper()
+			if math.IsNaN(float64(want)) {
+				if !math.IsNaN(float64(got)) {
+					t.Errorf("%s: %s got %v, want NaN", test.name, field, got)
+				}
+				return
+			}
+			if math.Abs(float64(got-want)) > 1e-3*max(math.Abs(float64(want)), 1e-3) {
+				t.Errorf("%s: %s got %v, want %v", test.name, field, got, want)
+			}
+		}
+		check("Frequency", got.Frequency, test.want.Frequency)
+		check("Period", got.Period, test.want.Period)
+		check("PeakToPeak", got.PeakToPeak, test.want.PeakToPeak)
+		check("RMS", got.RMS, test.want.RMS)
+		check("Mean", got.Mean, test.want.Mean)
+		check("RiseTime", got.RiseTime, test.want.RiseTime)
+	}
+}
+
+func TestFormatUnit(t *testing.T) {
+	tests := []struct {
+		value float32
+		want  string
+	}{
+		{float32(math.NaN()), "---"},
+		{float32(math.Inf(1)), "---"},
+		{0, "0V"},
+		{6.57e-11, "0V"},
+		{-2.5e-9, "-2.5nV"},
+		{3e-6, "3µV"},
+		{0.05, "50mV"},
+		{1.5, "1.5V"},
+		{2000, "2kV"},
+	}
+
+	for _, test := range tests {
+		if got := formatUnit(test.value, "V"); got != test.want {
+			t.Errorf("%v: got %q, want %q", test.value, got, test.want)
+		}
+	}
+}
@@ -1,10 +1,12 @@
 package scope
 
 import (
+	"image"
 	"image/color"
 
 	"gioui.org/f32"
 	"gioui.org/layout"
+	"gioui.org/op"
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
 	"gioui.org/widget/material"
@@ -12,12 +14,147 @@ import (
 	"github.com/egonelbre/expgio/oscillator/generator"
 )
 
+const (
+	// DivisionsX and DivisionsY are the number of graticule divisions.
+	DivisionsX = 10
+	DivisionsY = 8
+
+	// pretrigger is the number of divisions shown before the trigger.
+	pretrigger = 1
+	// autoTimeout is how long Auto mode waits for a trigger before free-running.
+	autoTimeout = 0.5
+)
+
 type Display struct {
 	Data generator.Data
+
+	// Trigger uses generator.ChannelA as the source.
+	Trigger    Trigger
+	TimePerDiv TimeDiv
+	Channels   [generator.Channels]Channel
+	Cursors    Cursors
+
+	// Measurements are calculated from the captured traces.
+	Measurements [generator.Channels]Measurement
+
+	// State describes the trigger state, e.g. "TRIG'D" or "WAIT".
+	State string
+
+	// traces are the captured part of the signals, starting from start.
+	traces [generator.Channels][]generator.Point
+	start  float32
+	// captures counts the captured traces, so that derived views
+	// know when to recalculate.
+	captures int
+	// lastTrigger is the time of the previous trigger.
+	lastTrigger float32
+	triggered   bool
+	stopped     bool
 }
 
 func NewDisplay() *Display {
-	return &Display{}
+	display := &Display{
+		TimePerDiv: 0.05,
+		Cursors:    NewCursors(),
+		State:      "WAIT",
+	}
+	for i := range display.Channels {
+		display.Channels[i] = Channel{
+			Color:       TraceColor(i),
+			VoltsPerDiv: 0.05,
+			Visible:     i < generator.Inputs,
+		}
+		display.Measurements[i] = Measure(nil)
+	}
+	return display
+}
+
+// Arm re-enables capturing in Single mode.
+func (display *Display) Arm() {
+	display.stopped = false
+	display.State = "WAIT"
+}
+
+// Reset clears the trigger state, e.g. when the data jumps in time.
+func (display *Display) Reset() {
+	display.lastTrigger = 0
+	display.triggered = false
+	display.stopped = false
+	display.State = "WAIT"
+}
+
+// Push captures a new trace from data according to the trigger settings.
+func (display *Display) Push(data generator.Data) {
+	display.Data = data
+	source := data.Channels[generator.ChannelA].Values
+	if display.stopped || len(source) < 2 {
+		return
+	}
+
+	// the time starts over after a generator epoch or when a replay loops
+	if source[len(source)-1].X < display.lastTrigger {
+		display.Reset()
+	}
+
+	span := float32(display.TimePerDiv) * DivisionsX
+	before := float32(display.TimePerDiv) * pretrigger
+	after := span - before
+	last := source[len(source)-1].X
+
+	notBefore := display.lastTrigger + float32(display.Trigger.Holdoff)
+	if !display.triggered {
+		notBefore = source[0].X
+	} else if notBefore <= display.lastTrigger {
+		// the same crossing is usually visible in several frames
+		notBefore = display.lastTrigger + (source[1].X - source[0].X)
+	}
+
+	if t, ok := display.Trigger.find(source, display.TriggerVolts(), before, after, notBefore); ok {
+		display.capture(data, t-before, span)
+		display.lastTrigger = t
+		display.triggered = true
+		display.State = "TRIG'D"
+		if display.Trigger.Mode == Single {
+			display.stopped = true
+			display.State = "STOP"
+		}
+		return
+	}
+
+	switch display.Trigger.Mode {
+	case Auto:
+		if !display.triggered || last-display.lastTrigger > autoTimeout {
+			display.capture(data, last-span, span)
+			display.triggered = false
+			display.State = "AUTO"
+		}
+	case Normal, Single:
+		if display.traces[generator.ChannelA] == nil || last-display.lastTrigger > autoTimeout {
+			display.State = "WAIT"
+		}
+	}
+}
+
+// TriggerVolts returns the trigger level in volts of the source channel.
+func (display *Display) TriggerVolts() float32 {
+	source := display.Channels[generator.ChannelA]
+	return (display.Trigger.Level - float32(source.Offset)) * float32(source.VoltsPerDiv)
+}
+
+// capture copies the values in [start, start+span] to the traces.
+func (display *Display) capture(data generator.Data, start, span float32) {
+	display.start = start
+	display.captures++
+	for c, channel := range data.Channels {
+		trace := display.traces[c][:0]
+		for _, v := range channel.Values {
+			if v.X >= start && v.X <= start+span {
+				trace = append(trace, v)
+			}
+		}
+		display.traces[c] = trace
+		display.Measurements[c] = Measure(trace)
+	}
 }
 
 func (display *Display) Layout(th *material.Theme, gtx layout.Context) layout.Dimensions {
@@ -28,42 +165,130 @@ func (display *Display) Layout(th *material.Theme, gtx layout.Context) layout.Di
 	size := gtx.Constraints.Max
 	paint.FillShape(gtx.Ops, black, clip.Rect{Max: size}.Op())
 
-	data := &display.Data
-
-	viewmin := data.Min
-	viewmax := data.Max
-
-	toDisplay := func(v generator.Point) f32.Point {
-		px := (viewmax.X - v.X) / (viewmax.X - viewmin.X)
-		py := (viewmax.Y - v.Y) / (viewmax.Y - viewmin.Y)
+	display.layoutGraticule(gtx)
 
+	div := f32.Point{
+		X: float32(size.X) / DivisionsX,
+		Y: float32(size.Y) / DivisionsY,
+	}
+	toDisplay := func(channel Channel, v generator.Point) f32.Point {
 		return f32.Point{
-			X: px * float32(size.X),
-			Y: py * float32(size.Y),
+			X: (v.X - display.start) / float32(display.TimePerDiv) * div.X,
+			Y: float32(size.Y)/2 - (v.Y/float32(channel.VoltsPerDiv)+float32(channel.Offset))*div.Y,
 		}
 	}
 
-	if len(data.Values) > 0 {
+	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+
+	for c, trace := range display.traces {
+		channel := display.Channels[c]
+		if !channel.Visible || len(trace) == 0 {
+			continue
+		}
+
 		path := clip.Path{}
 		path.Begin(gtx.Ops)
 
-		path.MoveTo(toDisplay(data.Values[0]))
-		for _, v := range data.Values {
-			path.LineTo(toDisplay(v))
+		path.MoveTo(toDisplay(channel, trace[0]))
+		for _, v := range trace {
+			path.LineTo(toDisplay(channel, v))
 		}
 
-		paint.FillShape(gtx.Ops, white, clip.Stroke{
+		paint.FillShape(gtx.Ops, channel.Color.NRGBA(), clip.Stroke{
 			Path:  path.End(),
 			Width: float32(gtx.Metric.Dp(1)),
 		}.Op())
 	}
 
+	// trigger level marker on the right edge
+	source := display.Channels[generator.ChannelA]
+	level := int(toDisplay(source, generator.Point{Y: display.TriggerVolts()}).Y)
+	marker := gtx.Metric.Dp(8)
+	paint.FillShape(gtx.Ops, triggerColor, clip.Rect{
+		Min: image.Pt(size.X-marker, level-gtx.Metric.Dp(1)),
+		Max: image.Pt(size.X, level+gtx.Metric.Dp(1)),
+	}.Op())
+
+	display.Cursors.Layout(th, gtx, display, div)
+	display.layoutLabels(th, gtx)
+
 	return layout.Dimensions{
 		Size: gtx.Constraints.Max,
 	}
 }
 
+// layoutLabels draws the trigger state and the scale of the visible channels.
+func (display *Display) layoutLabels(th *material.Theme, gtx layout.Context) {
+	defer op.Offset(image.Pt(gtx.Metric.Dp(4), gtx.Metric.Dp(4))).Push(gtx.Ops).Pop()
+
+	label := func(txt string, col color.NRGBA) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Right: 12}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(th, txt)
+				label.Color = col
+				return label.Layout(gtx)
+			})
+		})
+	}
+
+	children := []layout.FlexChild{
+		label(display.State, triggerColor),
+		label(display.TimePerDiv.String(), triggerColor),
+	}
+	for c, channel := range display.Channels {
+		if !channel.Visible {
+			continue
+		}
+		children = append(children, label(generator.Channel(c).String()+" "+channel.VoltsPerDiv.String(), channel.Color.NRGBA()))
+	}
+
+	layout.Flex{}.Layout(gtx, children...)
+}
+
+// layoutGraticule draws the division grid with a tick marked center axes.
+func (display *Display) layoutGraticule(gtx layout.Context) {
+	size := gtx.Constraints.Max
+	line := max(gtx.Metric.Dp(1), 1)
+
+	for i := 0; i <= DivisionsX; i++ {
+		x := i * (size.X - line) / DivisionsX
+		col := graticuleColor
+		if i == DivisionsX/2 {
+			col = axisColor
+		}
+		paint.FillShape(gtx.Ops, col, clip.Rect{Min: image.Pt(x, 0), Max: image.Pt(x+line, size.Y)}.Op())
+	}
+	for i := 0; i <= DivisionsY; i++ {
+		y := i * (size.Y - line) / DivisionsY
+		col := graticuleColor
+		if i == DivisionsY/2 {
+			col = axisColor
+		}
+		paint.FillShape(gtx.Ops, col, clip.Rect{Min: image.Pt(0, y), Max: image.Pt(size.X, y+line)}.Op())
+	}
+
+	// minor ticks on the center axes, 5 per division
+	tick := gtx.Metric.Dp(3)
+	center := image.Pt(size.X/2, size.Y/2)
+	for i := 0; i <= DivisionsX*5; i++ {
+		x := i * size.X / (DivisionsX * 5)
+		paint.FillShape(gtx.Ops, axisColor, clip.Rect{
+			Min: image.Pt(x, center.Y-tick), Max: image.Pt(x+line, center.Y+tick),
+		}.Op())
+	}
+	for i := 0; i <= DivisionsY*5; i++ {
+		y := i * size.Y / (DivisionsY * 5)
+		paint.FillShape(gtx.Ops, axisColor, clip.Rect{
+			Min: image.Pt(center.X-tick, y), Max: image.Pt(center.X+tick, y+line),
+		}.Op())
+	}
+}
+
 var (
 	white = color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
 	black = color.NRGBA{R: 0x00, G: 0x00, B: 0x00, A: 0xFF}
+
+	graticuleColor = color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0x20}
+	axisColor      = color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0x50}
+	triggerColor   = color.NRGBA{R: 0xFF, G: 0xCC, B: 0x00, A: 0xFF}
 )
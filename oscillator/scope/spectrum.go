@@ -0,0 +1,232 @@
+package scope
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/widget/material"
+
+	"github.com/egonelbre/expgio/oscillator/generator"
+)
+
+const (
+	// spectrumTop and spectrumBottom are the displayed magnitude range in dB.
+	spectrumTop    = 40
+	spectrumBottom = -120
+	// spectrumPeaks is the maximum number of peaks marked per channel.
+	spectrumPeaks = 3
+	// peakFloor is the magnitude below which peaks are ignored.
+	peakFloor = -80
+	// spectrumMinSamples is the minimum trace length for a spectrum,
+	// shorter traces don't have a frequency range to show.
+	spectrumMinSamples = 4
+)
+
+// Spectrum displays the frequency domain of the captured traces
+// of the visible channels of a Display.
+type Spectrum struct {
+	Display *Display
+	Window  FFTWindow
+
+	// cached is the spectrum of the capture and window in cachedKey.
+	cached    [generator.Channels]spectrumChannel
+	cachedKey spectrumKey
+}
+
+type spectrumKey struct {
+	captures int
+	window   FFTWindow
+}
+
+func NewSpectrum(display *Display) *Spectrum {
+	return &Spectrum{Display: display, Window: Hann}
+}
+
+// spectrumChannel is the calculated spectrum of a single channel.
+type spectrumChannel struct {
+	color     color.NRGBA
+	binWidth  float64 // Hz
+	magnitude []float64
+}
+
+// channels returns the spectrums of the visible channels.
+func (spectrum *Spectrum) channels() []spectrumChannel {
+	spectrum.update()
+
+	var result []spectrumChannel
+	for c, calculated := range spectrum.cached {
+		channel := spectrum.Display.Channels[c]
+		if !channel.Visible || calculated.magnitude == nil {
+			continue
+		}
+		calculated.color = channel.Color.NRGBA()
+		result = append(result, calculated)
+	}
+	return result
+}
+
+// update recalculates the spectrums when there's a new capture
+// or the window has changed.
+func (spectrum *Spectrum) update() {
+	key := spectrumKey{captures: spectrum.Display.captures, window: spectrum.Window}
+	if key == spectrum.cachedKey {
+		return
+	}
+	spectrum.cachedKey = key
+
+	for c, trace := range spectrum.Display.traces {
+		spectrum.cached[c] = spectrumChannel{}
+		if len(trace) < spectrumMinSamples {
+			continue
+		}
+
+		values := make([]float64, len(trace))
+		for i, v := range trace {
+			values[i] = float64(v.Y)
+		}
+		magnitude := magnitudes(values, spectrum.Window)
+
+		dt := float64(trace[1].X - trace[0].X)
+		n := (len(magnitude) - 1) * 2
+		spectrum.cached[c] = spectrumChannel{
+			binWidth:  1 / (dt * float64(n)),
+			magnitude: magnitude,
+		}
+	}
+}
+
+func (spectrum *Spectrum) Layout(th *material.Theme, gtx layout.Context) layout.Dimensions {
+	gtx.Constraints = layout.Exact(gtx.Constraints.Max)
+
+	size := gtx.Constraints.Max
+	paint.FillShape(gtx.Ops, black, clip.Rect{Max: size}.Op())
+	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+
+	channels := spectrum.channels()
+	if len(channels) == 0 {
+		return layout.Dimensions{Size: size}
+	}
+
+	// the first bin is DC, which can't be shown on a log axis
+	fmin := channels[0].binWidth
+	fmax := channels[0].binWidth * float64(len(channels[0].magnitude)-1)
+	toDisplay := func(freq, db float64) f32.Point {
+		return f32.Point{
+			X: float32(math.Log10(freq/fmin) / math.Log10(fmax/fmin) * float64(size.X)),
+			Y: float32((spectrumTop - db) / (spectrumTop - spectrumBottom) * float64(size.Y)),
+		}
+	}
+
+	spectrum.layoutGrid(th, gtx, fmin, fmax, toDisplay)
+
+	for _, channel := range channels {
+		path := clip.Path{}
+		path.Begin(gtx.Ops)
+		path.MoveTo(toDisplay(channel.binWidth, channel.magnitude[1]))
+		for i := 2; i < len(channel.magnitude); i++ {
+			path.LineTo(toDisplay(channel.binWidth*float64(i), channel.magnitude[i]))
+		}
+		paint.FillShape(gtx.Ops, channel.color, clip.Stroke{
+			Path:  path.End(),
+			Width: float32(gtx.Metric.Dp(1)),
+		}.Op())
+	}
+
+	for _, channel := range channels {
+		for _, bin := range peaks(channel.magnitude, spectrumPeaks) {
+			freq, db := channel.binWidth*float64(bin), channel.magnitude[bin]
+			at := toDisplay(freq, db).Round()
+
+			marker := gtx.Metric.Dp(4)
+			path := clip.Path{}
+			path.Begin(gtx.Ops)
+			path.MoveTo(f32.Pt(float32(at.X), float32(at.Y-marker)))
+			path.LineTo(f32.Pt(float32(at.X-marker), float32(at.Y-3*marker)))
+			path.LineTo(f32.Pt(float32(at.X+marker), float32(at.Y-3*marker)))
+			path.Close()
+			paint.FillShape(gtx.Ops, channel.color, clip.Outline{Path: path.End()}.Op())
+
+			layoutText(th, gtx, image.Pt(at.X+marker, at.Y-5*marker), formatFrequency(freq)+fmt.Sprintf(" %.0fdB", db), channel.color)
+		}
+	}
+
+	layoutText(th, gtx, image.Pt(gtx.Metric.Dp(4), gtx.Metric.Dp(4)), "FFT "+spectrum.Window.String(), triggerColor)
+
+	return layout.Dimensions{Size: size}
+}
+
+// layoutGrid draws the decade and dB lines.
+func (spectrum *Spectrum) layoutGrid(th *material.Theme, gtx layout.Context, fmin, fmax float64, toDisplay func(freq, db float64) f32.Point) {
+	size := gtx.Constraints.Max
+	line := max(gtx.Metric.Dp(1), 1)
+
+	for db := float64(spectrumBottom); db <= spectrumTop; db += 20 {
+		y := int(toDisplay(fmin, db).Y)
+		col := graticuleColor
+		if db == 0 {
+			col = axisColor
+		}
+		paint.FillShape(gtx.Ops, col, clip.Rect{Min: image.Pt(0, y), Max: image.Pt(size.X, y+line)}.Op())
+		layoutText(th, gtx, image.Pt(size.X-gtx.Metric.Dp(48), y), fmt.Sprintf("%+.0fdB", db), axisColor)
+	}
+
+	for decade := math.Pow(10, math.Floor(math.Log10(fmin))); decade <= fmax; decade *= 10 {
+		for k := 1; k < 10; k++ {
+			freq := decade * float64(k)
+			if freq < fmin || freq > fmax {
+				continue
+			}
+			x := int(toDisplay(freq, 0).X)
+			col := graticuleColor
+			if k == 1 {
+				col = axisColor
+				layoutText(th, gtx, image.Pt(x+gtx.Metric.Dp(2), size.Y-gtx.Metric.Dp(20)), formatFrequency(freq), axisColor)
+			}
+			paint.FillShape(gtx.Ops, col, clip.Rect{Min: image.Pt(x, 0), Max: image.Pt(x+line, size.Y)}.Op())
+		}
+	}
+}
+
+// peaks returns up to n bins of the largest local maxima, excluding DC.
+func peaks(magnitude []float64, n int) []int {
+	var candidates []int
+	for i := 1; i < len(magnitude)-1; i++ {
+		if magnitude[i] > peakFloor && magnitude[i] >= magnitude[i-1] && magnitude[i] > magnitude[i+1] {
+			candidates = append(candidates, i)
+		}
+	}
+	slices.SortFunc(candidates, func(a, b int) int {
+		switch {
+		case magnitude[a] > magnitude[b]:
+			return -1
+		case magnitude[a] < magnitude[b]:
+			return 1
+		default:
+			return a - b
+		}
+	})
+	return candidates[:min(n, len(candidates))]
+}
+
+func layoutText(th *material.Theme, gtx layout.Context, at image.Point, txt string, col color.NRGBA) {
+	defer op.Offset(at).Push(gtx.Ops).Pop()
+	gtx.Constraints.Min = image.Point{}
+	label := material.Caption(th, txt)
+	label.Color = col
+	label.Layout(gtx)
+}
+
+func formatFrequency(hz float64) string {
+	if hz >= 1000 {
+		return fmt.Sprintf("%.3gkHz", hz/1000)
+	}
+	return fmt.Sprintf("%.3gHz", hz)
+}
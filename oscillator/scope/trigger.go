@@ -0,0 +1,135 @@
+package scope
+
+import "github.com/egonelbre/expgio/oscillator/generator"
+
+// TriggerMode defines when the display captures a new trace.
+type TriggerMode byte
+
+const (
+	// Auto captures on trigger, but free-runs when there hasn't been
+	// a trigger for a while.
+	Auto = TriggerMode(iota)
+	// Normal captures only on trigger.
+	Normal
+	// Single captures once on trigger and stops until armed again.
+	Single
+)
+
+func (mode TriggerMode) String() string {
+	switch mode {
+	case Auto:
+		return "AUTO"
+	case Normal:
+		return "NORM"
+	case Single:
+		return "SNGL"
+	default:
+		return "???"
+	}
+}
+
+func (TriggerMode) Options() []TriggerMode {
+	return []TriggerMode{Auto, Normal, Single}
+}
+
+// Edge is the signal slope that triggers a capture.
+type Edge byte
+
+const (
+	Rising = Edge(iota)
+	Falling
+)
+
+func (edge Edge) String() string {
+	switch edge {
+	case Rising:
+		return "RISE"
+	case Falling:
+		return "FALL"
+	default:
+		return "???"
+	}
+}
+
+func (Edge) Options() []Edge {
+	return []Edge{Rising, Falling}
+}
+
+// Holdoff is the minimum time in seconds between two triggers.
+type Holdoff float32
+
+func (holdoff Holdoff) String() string {
+	return "HOLD " + formatUnit(float32(holdoff), "s")
+}
+
+func (Holdoff) Options() []Holdoff {
+	return []Holdoff{0, 0.01, 0.05, 0.1, 0.5}
+}
+
+// TimeDiv is the horizontal scale in seconds per division.
+type TimeDiv float32
+
+func (div TimeDiv) String() string {
+	return formatUnit(float32(div), "s") + "/div"
+}
+
+func (TimeDiv) Options() []TimeDiv {
+	return []TimeDiv{0.001, 0.002, 0.005, 0.01, 0.02, 0.05, 0.1}
+}
+
+// VoltDiv is the vertical scale in volts per division.
+type VoltDiv float32
+
+func (div VoltDiv) String() string {
+	return formatUnit(float32(div), "V") + "/div"
+}
+
+func (VoltDiv) Options() []VoltDiv {
+	return []VoltDiv{0.01, 0.02, 0.05, 0.1, 0.2, 0.5, 1, 2, 5}
+}
+
+// Trigger configures when the display captures a new trace.
+type Trigger struct {
+	Mode TriggerMode
+	Edge Edge
+	// Level is in divisions from the center of the screen, so that it
+	// stays in place when the scale of the source changes.
+	Level   float32 `form:"min=-4,max=4,step=0.1"`
+	Holdoff Holdoff `form:"label="`
+}
+
+// find returns the time of the latest crossing of level (in volts) in values,
+// such that [t-before, t+after] is inside the values.
+func (trigger *Trigger) find(values []generator.Point, level, before, after, notBefore float32) (float32, bool) {
+	if len(values) < 2 {
+		return 0, false
+	}
+	first, last := values[0].X, values[len(values)-1].X
+
+	for i := len(values) - 1; i > 0; i-- {
+		a, b := values[i-1], values[i]
+		var crossed bool
+		switch trigger.Edge {
+		case Rising:
+			crossed = a.Y < level && b.Y >= level
+		case Falling:
+			crossed = a.Y > level && b.Y <= level
+		}
+		if !crossed {
+			continue
+		}
+
+		// interpolate the exact crossing time
+		p := (level - a.Y) / (b.Y - a.Y)
+		t := a.X + (b.X-a.X)*p
+
+		switch {
+		case t+after > last:
+			continue
+		case t-before < first || t < notBefore:
+			return 0, false
+		}
+		return t, true
+	}
+	return 0, false
+}
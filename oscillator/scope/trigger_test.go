@@ -0,0 +1,45 @@
+package scope
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTriggerFind(t *testing.T) {
+	// 50Hz sine for 0.1s, crossing 0 upwards at 0, 0.02, ..., 0.08
+	// and downwards at 0.01, 0.03, ..., 0.09
+	values := sampled(1001, 1e-4, sine(50, 1))
+
+	tests := []struct {
+		name      string
+		edge      Edge
+		level     float32
+		before    float32
+		after     float32
+		notBefore float32
+		want      float32
+		ok        bool
+	}{
+		{"rising", Rising, 0, 0.01, 0.01, 0, 0.08, true},
+		{"rising too late", Rising, 0, 0.01, 0.03, 0, 0.06, true},
+		{"falling", Falling, 0, 0.01, 0.01, 0, 0.09, true},
+		{"falling too late", Falling, 0, 0.01, 0.02, 0, 0.07, true},
+		{"level", Rising, 0.5, 0.01, 0.01, 0, 0.08 + float32(math.Asin(0.5)/(2*math.Pi*50)), true},
+		{"holdoff", Rising, 0, 0.01, 0.01, 0.09, 0, false},
+		{"not enough before", Rising, 0, 0.09, 0.01, 0, 0, false},
+		{"out of range", Rising, 2, 0.01, 0.01, 0, 0, false},
+	}
+
+	for _, test := range tests {
+		trigger := Trigger{Edge: test.edge}
+		got, ok := trigger.find(values, test.level, test.before, test.after, test.notBefore)
+		if ok != test.ok || math.Abs(float64(got-test.want)) > 1e-5 {
+			t.Errorf("%s: got (%v, %v), want (%v, %v)", test.name, got, ok, test.want, test.ok)
+		}
+	}
+
+	var trigger Trigger
+	if _, ok := trigger.find(values[:1], 0, 0, 0, 0); ok {
+		t.Errorf("single value: triggered")
+	}
+}
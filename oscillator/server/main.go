@@ -0,0 +1,44 @@
+// server is a stand-in for a remote signal generator device.
+//
+// Run it and connect to it with `oscillator -connect localhost:7070`.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/egonelbre/expgio/oscillator/generator"
+)
+
+func main() {
+	listen := flag.String("listen", "localhost:7070", "listen on TCP `address` or unix socket path")
+	wav := flag.String("wav", "", "add WAV `file` as a signal source")
+	flag.Parse()
+
+	if *wav != "" {
+		source, err := generator.OpenWAV(*wav)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		generator.Register("WAV", func() generator.Source { return source })
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	listener, err := generator.Listen(*listen)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Fprintln(os.Stderr, "listening on", listener.Addr())
+
+	if err := generator.Serve(ctx, listener); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -16,6 +16,7 @@ type Spinnable[T comparable] interface {
 
 type Spin[T Spinnable[T]] struct {
 	Current *T // we use a pointer here to change values on the pending Config
+	Label   string
 
 	Next widget.Clickable
 	Prev widget.Clickable
@@ -51,16 +52,25 @@ func (spin *Spin[T]) update(gtx layout.Context) {
 
 func (spin *Spin[T]) Layout(th *material.Theme, gtx layout.Context) layout.Dimensions {
 	spin.update(gtx)
+
+	txt := (*spin.Current).String()
+	if spin.Label != "" {
+		txt = spin.Label + " " + txt
+	}
+	return layoutSpin(th, gtx, &spin.Next, &spin.Prev, txt)
+}
+
+// layoutSpin lays out the buttons with the text in between.
+func layoutSpin(th *material.Theme, gtx layout.Context, next, prev *widget.Clickable, txt string) layout.Dimensions {
 	return layout.Flex{
 		Axis:      layout.Horizontal,
 		Spacing:   layout.SpaceBetween,
 		Alignment: layout.Middle,
 	}.Layout(gtx,
-		layout.Rigid(material.Button(th, &spin.Next, "<").Layout),
+		layout.Rigid(material.Button(th, next, "<").Layout),
 		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
-			return layout.Center.Layout(gtx,
-				material.Body1(th, (*spin.Current).String()).Layout)
+			return layout.Center.Layout(gtx, material.Body1(th, txt).Layout)
 		}),
-		layout.Rigid(material.Button(th, &spin.Prev, ">").Layout),
+		layout.Rigid(material.Button(th, prev, ">").Layout),
 	)
 }